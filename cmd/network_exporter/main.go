@@ -5,32 +5,130 @@ import (
 	"fmt"
 	exporter "github.com/greenpau/network_exporter/pkg/network_exporter"
 	"github.com/prometheus/common/log"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 func main() {
 	var listenAddress string
 	var metricsPath string
+	var exporterMetricsPath string
+	var tlsCertFile string
+	var tlsKeyFile string
+	var tlsClientCAFile string
+	var tlsClientCertRequired bool
+	var authSubjects string
+	var authTokenFile string
 	var pollTimeout int
 	var pollInterval int
 	var isShowMetrics bool
+	var metricsFormat string
+	var isShowRules bool
+	var rulesThresholdsFile string
+	var isWriteDashboards bool
+	var dashboardsDir string
 	var isShowVersion bool
 	var logLevel string
 	var apiInventory string
 	var apiVault string
 	var apiVaultKey string
 	var authToken string
+	var labelStrategy string
+	var ifaceInclude string
+	var ifaceExclude string
+	var ifaceOnlyUp bool
+	var ifaceRates bool
+	var ifaceDescrKeys string
+	var ifaceDescrRegex string
+	var ifaceDescrDelimiter string
+	var rawResponses int
+	var apiRecordDir string
+	var apiReplayDir string
+	var configFile string
+	var inventoryRefresh int
+	var netboxURL string
+	var netboxTokenFile string
+	var netboxSites string
+	var netboxRoles string
+	var netboxTags string
+	var netboxStatuses string
+	var remoteWriteURL string
+	var remoteWriteInterval int
+	var remoteWriteBatchSize int
+	var remoteWriteBufferSize int
+	var remoteWriteJob string
+	var remoteWriteLabels string
+	var remoteWriteUsername string
+	var remoteWritePasswordFile string
+	var remoteWriteTokenFile string
+	var credsEnvPrefix string
+	var credsDir string
+	var credsCommand string
+	var credsVaultAddr string
+	var credsVaultPath string
+	var credsVaultTokenFile string
+	var isConfigCheck bool
 
 	flag.StringVar(&listenAddress, "web.listen-address", ":9533", "Address to listen on for web interface and telemetry.")
 	flag.StringVar(&metricsPath, "web.telemetry-path", "/metrics", "Path under which to expose metrics.")
+	flag.StringVar(&exporterMetricsPath, "web.exporter-telemetry-path", "/exporter/metrics", "Path under which to expose the metrics about the exporter itself.")
+	flag.StringVar(&tlsCertFile, "web.tls-cert", "", "The TLS certificate file. When set, the exporter serves HTTPS.")
+	flag.StringVar(&tlsKeyFile, "web.tls-key", "", "The TLS private key file.")
+	flag.StringVar(&tlsClientCAFile, "web.client-ca", "", "The CA certificates file for verifying client certificates.")
+	flag.BoolVar(&tlsClientCertRequired, "web.client-cert-required", false, "Reject the clients without a valid client certificate.")
 	flag.IntVar(&pollTimeout, "api.timeout", 5, "Timeout on requests to network devices.")
 	flag.IntVar(&pollInterval, "api.poll-interval", 15, "The minimum interval (in seconds) between collections from a network device.")
-	flag.StringVar(&apiInventory, "api.inventory", "/etc/network-exporter/hosts", "Node inventory file")
+	flag.StringVar(&apiInventory, "api.inventory", "/etc/network-exporter/hosts", "Node inventory: Ansible INI or YAML file, or dynamic inventory script")
+	flag.IntVar(&inventoryRefresh, "api.inventory.refresh-interval", 0, "The interval (in seconds) between inventory refreshes; 0 disables the refreshes")
+	flag.StringVar(&netboxURL, "netbox.url", "", "The URL of NetBox; when set, the nodes come from NetBox instead of the inventory file")
+	flag.StringVar(&netboxTokenFile, "netbox.token-file", "", "The file with NetBox API token; NETBOX_TOKEN environment variable by default")
+	flag.StringVar(&netboxSites, "netbox.site", "", "The comma-separated list of NetBox site slugs")
+	flag.StringVar(&netboxRoles, "netbox.role", "", "The comma-separated list of NetBox device role slugs")
+	flag.StringVar(&netboxTags, "netbox.tag", "", "The comma-separated list of NetBox tag slugs")
+	flag.StringVar(&netboxStatuses, "netbox.status", "active", "The comma-separated list of NetBox device statuses")
 	flag.StringVar(&apiVault, "api.vault", "/etc/network-exporter/vault.yml", "Node credentials vault")
 	flag.StringVar(&apiVaultKey, "api.vault.key", "/etc/network-exporter/vault.key", "The key to the vault")
+	flag.StringVar(&apiRecordDir, "api.record-dir", "", "The directory for writing device API responses to fixture files")
+	flag.StringVar(&apiReplayDir, "api.replay-dir", "", "The directory with fixture files serving collectors instead of devices")
+	flag.StringVar(&credsEnvPrefix, "credentials.env-prefix", "", "The prefix of the environment variables with node credentials, e.g. NETWORK_EXPORTER_CRED")
+	flag.StringVar(&credsDir, "credentials.dir", "", "The directory with node credentials, a subdirectory per credential, e.g. Kubernetes secret mounts")
+	flag.StringVar(&credsCommand, "credentials.command", "", "The command printing node credentials in JSON format")
+	flag.StringVar(&credsVaultAddr, "credentials.vault-addr", os.Getenv("VAULT_ADDR"), "The address of HashiCorp Vault with node credentials")
+	flag.StringVar(&credsVaultPath, "credentials.vault-path", "", "The path to HashiCorp Vault KV secret with node credentials, e.g. secret/data/network-exporter")
+	flag.StringVar(&credsVaultTokenFile, "credentials.vault-token-file", "", "The file with HashiCorp Vault token; VAULT_TOKEN environment variable by default")
+	flag.StringVar(&remoteWriteURL, "remote-write.url", "", "The URL of Prometheus remote write endpoint; when set, the exporter pushes the metrics of its nodes")
+	flag.IntVar(&remoteWriteInterval, "remote-write.interval", 60, "The interval (in seconds) between pushes")
+	flag.IntVar(&remoteWriteBatchSize, "remote-write.batch-size", 500, "The maximum number of samples in a remote write request")
+	flag.IntVar(&remoteWriteBufferSize, "remote-write.buffer-size", 100000, "The maximum number of samples kept for retries")
+	flag.StringVar(&remoteWriteJob, "remote-write.job", "network-exporter", "The value of job label of the pushed series")
+	flag.StringVar(&remoteWriteLabels, "remote-write.external-labels", "", "The comma-separated list of key=value labels added to the pushed series")
+	flag.StringVar(&remoteWriteUsername, "remote-write.username", "", "The username for remote write endpoint")
+	flag.StringVar(&remoteWritePasswordFile, "remote-write.password-file", "", "The file with the password for remote write endpoint")
+	flag.StringVar(&remoteWriteTokenFile, "remote-write.bearer-token-file", "", "The file with the bearer token for remote write endpoint")
 	flag.StringVar(&authToken, "auth.token", "anonymous", "The X-Token for accessing the exporter itself")
+	flag.StringVar(&authTokenFile, "auth.token-file", "", "The YAML file with hashed X-Tokens and their scopes")
+	flag.StringVar(&authSubjects, "auth.client-subjects", "", "The comma-separated list of client certificate subjects allowed to access the exporter itself")
+	flag.StringVar(&labelStrategy, "labels.strategy", "uuid", "The values of node, iface, and vlan labels: uuid, name, or both")
+	flag.StringVar(&ifaceInclude, "iface.include", "", "The regular expression matching the names of the interfaces to export")
+	flag.StringVar(&ifaceExclude, "iface.exclude", "", "The regular expression matching the names of the interfaces not to export")
+	flag.BoolVar(&ifaceOnlyUp, "iface.only-up", false, "Export only the interfaces in up state")
+	flag.BoolVar(&ifaceRates, "iface.rates", false, "Export the input and output bits per second and the utilization of interfaces computed by the exporter")
+	flag.StringVar(&ifaceDescrKeys, "iface.descr.keys", "", "The comma-separated list of interface description keys exported as labels of net_iface_description_info")
+	flag.StringVar(&ifaceDescrRegex, "iface.descr.regex", "", "The regular expression with named groups for parsing interface descriptions; key=value parsing by default")
+	flag.StringVar(&ifaceDescrDelimiter, "iface.descr.delimiter", ";", "The delimiter between key=value pairs in interface descriptions")
+	flag.IntVar(&rawResponses, "debug.raw-responses", 5, "The number of the last device responses kept per node and subsystem for /debug/raw endpoint; 0 disables the endpoint")
+	flag.StringVar(&configFile, "config.file", "", "The YAML configuration file; the arguments override its settings")
+	flag.BoolVar(&isConfigCheck, "config.check", false, "Validate the configuration file and exit")
 	flag.BoolVar(&isShowMetrics, "metrics", false, "Display available metrics")
+	flag.StringVar(&metricsFormat, "metrics.format", "markdown", "The format of the metrics displayed by -metrics: markdown, json, or yaml")
+	flag.BoolVar(&isShowRules, "rules", false, "Display Prometheus alerting and recording rules")
+	flag.StringVar(&rulesThresholdsFile, "rules.thresholds", "", "The YAML file with the thresholds of the rules displayed by -rules")
+	flag.BoolVar(&isWriteDashboards, "dashboards", false, "Write Grafana dashboards to the directory set by -dashboards.dir")
+	flag.StringVar(&dashboardsDir, "dashboards.dir", ".", "The directory for the Grafana dashboards written by -dashboards")
 	flag.BoolVar(&isShowVersion, "version", false, "version information")
 	flag.StringVar(&logLevel, "log.level", "info", "logging severity level")
 
@@ -43,16 +141,183 @@ func main() {
 	flag.Usage = usageHelp
 	flag.Parse()
 
+	isFlagSet := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		isFlagSet[f.Name] = true
+	})
+
+	if err := log.Base().SetLevel(logLevel); err != nil {
+		log.Errorf(err.Error())
+		os.Exit(1)
+	}
+
+	var cfg *exporter.Config
+	if configFile != "" {
+		var err error
+		cfg, err = exporter.LoadConfig(configFile)
+		if err != nil {
+			log.Errorf("%s failed to load config: %s", exporter.GetExporterName(), err)
+			os.Exit(1)
+		}
+	}
+	if isConfigCheck {
+		if cfg == nil {
+			log.Errorf("%s: -config.check requires -config.file", exporter.GetExporterName())
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "OK: %s\n", configFile)
+		os.Exit(0)
+	}
+	authTokens := []string{}
+	if cfg != nil {
+		// the settings in the configuration file apply unless the
+		// corresponding arguments were set explicitly
+		setString := func(name string, dst *string, v string) {
+			if !isFlagSet[name] && v != "" {
+				*dst = v
+			}
+		}
+		setString("web.listen-address", &listenAddress, cfg.Listen.Address)
+		setString("web.telemetry-path", &metricsPath, cfg.Listen.MetricsPath)
+		setString("web.exporter-telemetry-path", &exporterMetricsPath, cfg.Listen.ExporterMetricsPath)
+		setString("web.tls-cert", &tlsCertFile, cfg.Listen.TLSCert)
+		setString("web.tls-key", &tlsKeyFile, cfg.Listen.TLSKey)
+		setString("web.client-ca", &tlsClientCAFile, cfg.Listen.ClientCA)
+		if !isFlagSet["web.client-cert-required"] && cfg.Listen.ClientCertRequired {
+			tlsClientCertRequired = true
+		}
+		setString("auth.token-file", &authTokenFile, cfg.Auth.TokenFile)
+		setString("auth.client-subjects", &authSubjects, strings.Join(cfg.Auth.ClientSubjects, ","))
+		setString("api.inventory", &apiInventory, cfg.Inventory.File)
+		setString("api.vault", &apiVault, cfg.Inventory.Vault)
+		setString("api.vault.key", &apiVaultKey, cfg.Inventory.VaultKey)
+		if !isFlagSet["api.inventory.refresh-interval"] && cfg.Inventory.RefreshInterval > 0 {
+			inventoryRefresh = cfg.Inventory.RefreshInterval
+		}
+		setString("netbox.url", &netboxURL, cfg.Inventory.Netbox.URL)
+		setString("netbox.site", &netboxSites, strings.Join(cfg.Inventory.Netbox.Sites, ","))
+		setString("netbox.role", &netboxRoles, strings.Join(cfg.Inventory.Netbox.Roles, ","))
+		setString("netbox.tag", &netboxTags, strings.Join(cfg.Inventory.Netbox.Tags, ","))
+		setString("netbox.status", &netboxStatuses, strings.Join(cfg.Inventory.Netbox.Statuses, ","))
+		setString("remote-write.url", &remoteWriteURL, cfg.RemoteWrite.URL)
+		setString("remote-write.job", &remoteWriteJob, cfg.RemoteWrite.Job)
+		setString("remote-write.username", &remoteWriteUsername, cfg.RemoteWrite.Username)
+		setString("remote-write.password-file", &remoteWritePasswordFile, cfg.RemoteWrite.PasswordFile)
+		setString("remote-write.bearer-token-file", &remoteWriteTokenFile, cfg.RemoteWrite.BearerTokenFile)
+		setInt := func(name string, dst *int, v int) {
+			if !isFlagSet[name] && v > 0 {
+				*dst = v
+			}
+		}
+		setInt("remote-write.interval", &remoteWriteInterval, cfg.RemoteWrite.Interval)
+		setInt("remote-write.batch-size", &remoteWriteBatchSize, cfg.RemoteWrite.BatchSize)
+		setInt("remote-write.buffer-size", &remoteWriteBufferSize, cfg.RemoteWrite.BufferSize)
+		if !isFlagSet["remote-write.external-labels"] {
+			labels := []string{}
+			for k, v := range cfg.RemoteWrite.ExternalLabels {
+				labels = append(labels, k+"="+v)
+			}
+			remoteWriteLabels = strings.Join(labels, ",")
+		}
+		setString("credentials.env-prefix", &credsEnvPrefix, cfg.Credentials.EnvPrefix)
+		setString("credentials.dir", &credsDir, cfg.Credentials.Dir)
+		setString("credentials.command", &credsCommand, cfg.Credentials.Command)
+		setString("credentials.vault-addr", &credsVaultAddr, cfg.Credentials.VaultAddr)
+		setString("credentials.vault-path", &credsVaultPath, cfg.Credentials.VaultPath)
+		if !isFlagSet["auth.token"] {
+			authTokens = append(authTokens, cfg.Auth.Tokens...)
+		}
+		if cfg.Inventory.Vault != "" {
+			isFlagSet["api.vault"] = true
+		}
+	}
+	if isFlagSet["auth.token"] || (len(authTokens) == 0 && authTokenFile == "") {
+		authTokens = append(authTokens, authToken)
+	}
+
 	opts := exporter.Options{
 		Timeout:       pollTimeout,
 		InventoryFile: apiInventory,
 		VaultFile:     apiVault,
 		VaultKeyFile:  apiVaultKey,
+		LabelStrategy: labelStrategy,
+		IfaceInclude:  ifaceInclude,
+		IfaceExclude:  ifaceExclude,
+		IfaceOnlyUp:   ifaceOnlyUp,
+		IfaceRates:    ifaceRates,
+		RawResponses:  rawResponses,
+		RecordDir:     apiRecordDir,
+		ReplayDir:     apiReplayDir,
+		Config:        cfg,
+		Settings:      &exporter.NodeSettings{},
 	}
-
-	if err := log.Base().SetLevel(logLevel); err != nil {
-		log.Errorf(err.Error())
-		os.Exit(1)
+	// the arguments passed explicitly override the configuration file
+	if isFlagSet["api.timeout"] {
+		opts.Settings.Timeout = pollTimeout
+	}
+	if isFlagSet["api.poll-interval"] {
+		opts.Settings.PollInterval = int64(pollInterval)
+	}
+	if netboxURL != "" {
+		opts.Netbox = &exporter.NetboxOptions{
+			URL:      netboxURL,
+			Token:    os.Getenv("NETBOX_TOKEN"),
+			Sites:    splitList(netboxSites),
+			Roles:    splitList(netboxRoles),
+			Tags:     splitList(netboxTags),
+			Statuses: splitList(netboxStatuses),
+		}
+		if cfg != nil && cfg.Inventory.Netbox.Token != "" {
+			opts.Netbox.Token = cfg.Inventory.Netbox.Token
+		}
+		if netboxTokenFile != "" {
+			data, err := ioutil.ReadFile(netboxTokenFile)
+			if err != nil {
+				log.Errorf("%s failed to read netbox token file: %s", exporter.GetExporterName(), err)
+				os.Exit(1)
+			}
+			opts.Netbox.Token = strings.TrimSpace(string(data))
+		}
+	}
+	opts.Credentials = exporter.CredentialOptions{
+		EnvPrefix: credsEnvPrefix,
+		Dir:       credsDir,
+		Command:   credsCommand,
+		VaultPath: credsVaultPath,
+	}
+	if credsVaultPath != "" {
+		opts.Credentials.VaultAddr = credsVaultAddr
+		if cfg != nil && !isFlagSet["credentials.vault-token-file"] {
+			opts.Credentials.VaultToken = cfg.Credentials.VaultToken
+		}
+		if credsVaultTokenFile != "" {
+			data, err := ioutil.ReadFile(credsVaultTokenFile)
+			if err != nil {
+				log.Errorf("%s failed to read vault token file: %s", exporter.GetExporterName(), err)
+				os.Exit(1)
+			}
+			opts.Credentials.VaultToken = strings.TrimSpace(string(data))
+		}
+	}
+	if apiReplayDir != "" && !isFlagSet["api.vault"] {
+		// in the replay mode, the vault is used only when set explicitly
+		opts.VaultFile = ""
+	}
+	if opts.Credentials != (exporter.CredentialOptions{}) && !isFlagSet["api.vault"] {
+		// the other credential sources replace the default vault
+		opts.VaultFile = ""
+	}
+	if opts.VaultFile != "" && !isFlagSet["api.vault"] {
+		// the default vault is optional, e.g. the inventory holds the
+		// credentials in ansible_user and ansible_password variables
+		if _, err := os.Stat(opts.VaultFile); os.IsNotExist(err) {
+			opts.VaultFile = ""
+		}
+	}
+	if ifaceDescrKeys != "" {
+		opts.IfaceDescrKeys = strings.Split(ifaceDescrKeys, ",")
+		opts.IfaceDescrRegex = ifaceDescrRegex
+		opts.IfaceDescrDelimiter = ifaceDescrDelimiter
 	}
 
 	if isShowVersion {
@@ -67,7 +332,48 @@ func main() {
 
 	if isShowMetrics {
 		e := &exporter.NetworkNode{}
-		fmt.Fprintf(os.Stdout, "%s\n", e.GetMetricsTable())
+		if err := e.SetLabelStrategy(labelStrategy); err != nil {
+			log.Errorf(err.Error())
+			os.Exit(1)
+		}
+		catalog, err := e.GetMetricCatalog(metricsFormat)
+		if err != nil {
+			log.Errorf(err.Error())
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", catalog)
+		os.Exit(0)
+	}
+
+	if isShowRules {
+		thresholds, err := exporter.LoadRuleThresholds(rulesThresholdsFile)
+		if err != nil {
+			log.Errorf(err.Error())
+			os.Exit(1)
+		}
+		rules, err := exporter.GetRules(thresholds)
+		if err != nil {
+			log.Errorf(err.Error())
+			os.Exit(1)
+		}
+		fmt.Fprint(os.Stdout, rules)
+		os.Exit(0)
+	}
+
+	if isWriteDashboards {
+		dashboards, err := exporter.GetDashboards()
+		if err != nil {
+			log.Errorf(err.Error())
+			os.Exit(1)
+		}
+		for name, dashboard := range dashboards {
+			fp := filepath.Join(dashboardsDir, name)
+			if err := ioutil.WriteFile(fp, []byte(dashboard), 0644); err != nil {
+				log.Errorf("error writing dashboard: %s", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stdout, "%s\n", fp)
+		}
 		os.Exit(0)
 	}
 
@@ -80,24 +386,158 @@ func main() {
 		os.Exit(1)
 	}
 	e.SetPollInterval(int64(pollInterval))
-	if err := e.AddAuthenticationToken(authToken); err != nil {
-		log.Errorf("%s failed to add authentication token: %s", exporter.GetExporterName(), err)
-		os.Exit(1)
+	for _, token := range authTokens {
+		if err := e.AddAuthenticationToken(token); err != nil {
+			log.Errorf("%s failed to add authentication token: %s", exporter.GetExporterName(), err)
+			os.Exit(1)
+		}
+	}
+	if authTokenFile != "" {
+		if err := e.LoadAuthenticationTokens(authTokenFile); err != nil {
+			log.Errorf("%s failed to load authentication tokens: %s", exporter.GetExporterName(), err)
+			os.Exit(1)
+		}
+		log.Infof("Token file: %s", authTokenFile)
 	}
 
-	log.Infof("Inventory file: %s", e.InventoryFile)
-	log.Infof("Vault file: %s", e.VaultFile)
-	log.Infof("Vault key file: %s", e.VaultKeyFile)
+	for _, subject := range strings.Split(authSubjects, ",") {
+		if subject == "" {
+			continue
+		}
+		if err := e.AddAuthenticationSubject(subject); err != nil {
+			log.Errorf("%s failed to add authentication subject: %s", exporter.GetExporterName(), err)
+			os.Exit(1)
+		}
+	}
+
+	if netboxURL != "" {
+		log.Infof("NetBox: %s", netboxURL)
+	} else {
+		log.Infof("Inventory file: %s", e.InventoryFile)
+	}
+	if inventoryRefresh > 0 {
+		e.RefreshInventory(time.Duration(inventoryRefresh) * time.Second)
+		log.Infof("Inventory refresh interval: %d seconds", inventoryRefresh)
+	}
+	if e.VaultFile != "" {
+		log.Infof("Vault file: %s", e.VaultFile)
+		log.Infof("Vault key file: %s", e.VaultKeyFile)
+	}
+	for _, s := range []struct{ name, value string }{
+		{"environment variables", credsEnvPrefix},
+		{"directory", credsDir},
+		{"command", credsCommand},
+		{"HashiCorp Vault secret", credsVaultPath},
+	} {
+		if s.value != "" {
+			log.Infof("Credentials %s: %s", s.name, s.value)
+		}
+	}
+	if configFile != "" {
+		log.Infof("Config file: %s", configFile)
+	}
 	log.Infof("Minimal scrape interval: %d seconds", e.GetPollInterval())
 
+	if remoteWriteURL != "" {
+		rw := exporter.RemoteWriteOptions{
+			URL:            remoteWriteURL,
+			Interval:       time.Duration(remoteWriteInterval) * time.Second,
+			BatchSize:      remoteWriteBatchSize,
+			BufferSize:     remoteWriteBufferSize,
+			Job:            remoteWriteJob,
+			ExternalLabels: make(map[string]string),
+			Username:       remoteWriteUsername,
+		}
+		for _, kv := range splitList(remoteWriteLabels) {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 {
+				log.Errorf("%s: invalid external label %q", exporter.GetExporterName(), kv)
+				os.Exit(1)
+			}
+			rw.ExternalLabels[parts[0]] = parts[1]
+		}
+		for _, f := range []struct {
+			fp  string
+			dst *string
+		}{
+			{remoteWritePasswordFile, &rw.Password},
+			{remoteWriteTokenFile, &rw.BearerToken},
+		} {
+			if f.fp == "" {
+				continue
+			}
+			data, err := ioutil.ReadFile(f.fp)
+			if err != nil {
+				log.Errorf("%s failed to read remote write credentials: %s", exporter.GetExporterName(), err)
+				os.Exit(1)
+			}
+			*f.dst = strings.TrimSpace(string(data))
+		}
+		if err := e.StartRemoteWrite(rw); err != nil {
+			log.Errorf("%s failed to start remote write: %s", exporter.GetExporterName(), err)
+			os.Exit(1)
+		}
+		log.Infof("Remote write: %s, every %d seconds", remoteWriteURL, remoteWriteInterval)
+	}
+
 	http.HandleFunc(metricsPath, func(w http.ResponseWriter, r *http.Request) {
 		e.Scrape(w, r)
 	})
 
+	http.HandleFunc(exporterMetricsPath, func(w http.ResponseWriter, r *http.Request) {
+		e.ExporterMetrics(w, r)
+	})
+
+	http.HandleFunc(exporter.NodeDetailPath, func(w http.ResponseWriter, r *http.Request) {
+		e.NodeDetail(metricsPath, w, r)
+	})
+
+	http.HandleFunc(exporter.RawResponsesPath, func(w http.ResponseWriter, r *http.Request) {
+		e.RawResponse(w, r)
+	})
+
+	http.HandleFunc(exporter.APINodesPath, func(w http.ResponseWriter, r *http.Request) {
+		e.NodeStatusAPI(w, r)
+	})
+
+	http.HandleFunc(exporter.APINodesPath+"/", func(w http.ResponseWriter, r *http.Request) {
+		e.NodeStatusAPI(w, r)
+	})
+
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		e.Summary(metricsPath, w, r)
 	})
 
+	if tlsCertFile != "" || tlsKeyFile != "" {
+		tlsConfig, err := exporter.NewTLSConfig(exporter.TLSOptions{
+			CertFile:          tlsCertFile,
+			KeyFile:           tlsKeyFile,
+			ClientCAFile:      tlsClientCAFile,
+			RequireClientCert: tlsClientCertRequired,
+		})
+		if err != nil {
+			log.Errorf("%s failed to init TLS: %s", exporter.GetExporterName(), err)
+			os.Exit(1)
+		}
+		server := &http.Server{
+			Addr:      listenAddress,
+			TLSConfig: tlsConfig,
+		}
+		log.Infoln("Listening on", listenAddress, "(HTTPS)")
+		log.Fatal(server.ListenAndServeTLS("", ""))
+	}
+
 	log.Infoln("Listening on", listenAddress)
 	log.Fatal(http.ListenAndServe(listenAddress, nil))
 }
+
+// splitList returns the items of a comma-separated list.
+func splitList(s string) []string {
+	items := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			items = append(items, v)
+		}
+	}
+	return items
+}
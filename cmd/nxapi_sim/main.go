@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	simulator "github.com/greenpau/network_exporter/pkg/nxapi_sim"
+	"github.com/prometheus/common/log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func main() {
+	var listenAddress string
+	var hostname string
+	var username string
+	var password string
+	var faultAuth bool
+	var faultLatency time.Duration
+	var faultInternalError bool
+	var faultMalformedJSON bool
+	var faultCommands string
+	var enableTLS bool
+	var logLevel string
+
+	flag.StringVar(&listenAddress, "listen", "127.0.0.1:8224", "Address to listen on for NX-API requests.")
+	flag.StringVar(&hostname, "hostname", "ny-sw01", "The hostname of the simulated switch")
+	flag.StringVar(&username, "username", "admin", "The username accepted by the simulated switch")
+	flag.StringVar(&password, "password", "cisco", "The password accepted by the simulated switch")
+	flag.BoolVar(&faultAuth, "fault.auth", false, "Reject all requests with 401 Unauthorized")
+	flag.DurationVar(&faultLatency, "fault.latency", 0, "Delay all responses, e.g. 2s")
+	flag.BoolVar(&faultInternalError, "fault.http-500", false, "Respond with 500 Internal Server Error")
+	flag.BoolVar(&faultMalformedJSON, "fault.malformed-json", false, "Respond with truncated JSON documents")
+	flag.StringVar(&faultCommands, "fault.commands", "", "Comma-separated commands affected by 500 and malformed JSON faults; all by default")
+	flag.BoolVar(&enableTLS, "tls", false, "Serve HTTPS with a self-signed certificate")
+	flag.StringVar(&logLevel, "log.level", "info", "logging severity level")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "\nnxapi-sim - Cisco NX-API Simulator\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: nxapi-sim [arguments]\n\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nDocumentation: https://github.com/greenpau/network_exporter/\n\n")
+	}
+	flag.Parse()
+
+	if err := log.Base().SetLevel(logLevel); err != nil {
+		log.Errorf(err.Error())
+		os.Exit(1)
+	}
+
+	opts := simulator.Options{
+		Hostname: hostname,
+		Username: username,
+		Password: password,
+		Faults: simulator.Faults{
+			AuthFailure:   faultAuth,
+			Latency:       faultLatency,
+			InternalError: faultInternalError,
+			MalformedJSON: faultMalformedJSON,
+		},
+	}
+	for _, c := range strings.Split(faultCommands, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			opts.Faults.Commands = append(opts.Faults.Commands, c)
+		}
+	}
+
+	sim := simulator.New(opts)
+	http.Handle("/ins", sim)
+	log.Infof("Simulating %s switch, credentials: %s/%s", hostname, username, password)
+	if enableTLS {
+		cert, err := simulator.NewSelfSignedCertificate(hostname)
+		if err != nil {
+			log.Errorf("nxapi-sim failed to init TLS: %s", err)
+			os.Exit(1)
+		}
+		server := &http.Server{
+			Addr:      listenAddress,
+			TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		}
+		log.Infoln("Listening on", listenAddress, "(HTTPS)")
+		log.Fatal(server.ListenAndServeTLS("", ""))
+	}
+
+	log.Infoln("Listening on", listenAddress)
+	log.Fatal(http.ListenAndServe(listenAddress, nil))
+}
@@ -0,0 +1,473 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/common/log"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ansibleInventory is an Ansible inventory read from a YAML file or from
+// the output of a dynamic inventory script.
+type ansibleInventory struct {
+	groups map[string]*ansibleGroup
+	// the variables of the hosts set in the inventory itself
+	hosts map[string]map[string]string
+}
+
+type ansibleGroup struct {
+	vars     map[string]string
+	hosts    []string
+	children []string
+}
+
+// ansibleYAMLGroup is a group in Ansible YAML inventory.
+type ansibleYAMLGroup struct {
+	Hosts    map[string]map[string]interface{} `yaml:"hosts"`
+	Vars     map[string]interface{}            `yaml:"vars"`
+	Children map[string]*ansibleYAMLGroup      `yaml:"children"`
+}
+
+func newAnsibleInventory() *ansibleInventory {
+	return &ansibleInventory{
+		groups: make(map[string]*ansibleGroup),
+		hosts:  make(map[string]map[string]string),
+	}
+}
+
+func (inv *ansibleInventory) getGroup(name string) *ansibleGroup {
+	g, exists := inv.groups[name]
+	if !exists {
+		g = &ansibleGroup{vars: make(map[string]string)}
+		inv.groups[name] = g
+	}
+	return g
+}
+
+func (inv *ansibleInventory) addHost(group, host string, vars map[string]interface{}) {
+	g := inv.getGroup(group)
+	g.hosts = append(g.hosts, host)
+	if _, exists := inv.hosts[host]; !exists {
+		inv.hosts[host] = make(map[string]string)
+	}
+	setAnsibleVariables(inv.hosts[host], vars)
+}
+
+func (inv *ansibleInventory) addChild(group, child string) {
+	g := inv.getGroup(group)
+	g.children = append(g.children, child)
+	inv.getGroup(child)
+}
+
+// setAnsibleVariables sets the scalar variables, i.e. strings, numbers,
+// and booleans. The lists and dictionaries are not supported.
+func setAnsibleVariables(dst map[string]string, vars map[string]interface{}) {
+	for k, v := range vars {
+		switch v := v.(type) {
+		case string:
+			dst[k] = v
+		case int:
+			dst[k] = strconv.Itoa(v)
+		case float64:
+			dst[k] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			dst[k] = strconv.FormatBool(v)
+		case nil:
+			dst[k] = ""
+		default:
+			log.Debugf("the variable '%s' was skipped because its type %T is unsupported", k, v)
+		}
+	}
+}
+
+// parseAnsibleYAMLInventory parses Ansible inventory in YAML format, e.g.
+// all: {children: {ny4-cisco: {hosts: {ny-sw01: {os: cisco_nxos}}}}}.
+func parseAnsibleYAMLInventory(data []byte) (*ansibleInventory, error) {
+	groups := make(map[string]*ansibleYAMLGroup)
+	if err := yaml.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("error parsing yaml inventory: %s", err)
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("error parsing yaml inventory: no groups")
+	}
+	inv := newAnsibleInventory()
+	var walk func(name string, g *ansibleYAMLGroup)
+	walk = func(name string, g *ansibleYAMLGroup) {
+		group := inv.getGroup(name)
+		if g == nil {
+			return
+		}
+		setAnsibleVariables(group.vars, g.Vars)
+		for host, vars := range g.Hosts {
+			inv.addHost(name, host, vars)
+		}
+		for child, c := range g.Children {
+			inv.addChild(name, child)
+			walk(child, c)
+		}
+	}
+	for name, g := range groups {
+		walk(name, g)
+	}
+	return inv, nil
+}
+
+// parseAnsibleINIGroups parses the groups of Ansible inventory in INI
+// format, i.e. the hosts of the groups and their children. The variables
+// are left to the INI inventory parser.
+func parseAnsibleINIGroups(data []byte) *ansibleInventory {
+	inv := newAnsibleInventory()
+	group, section := "ungrouped", ""
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group = strings.TrimSpace(line[1 : len(line)-1])
+			section = ""
+			if i := strings.Index(group, ":"); i > 0 {
+				group, section = group[:i], group[i+1:]
+			}
+			inv.getGroup(group)
+			continue
+		}
+		name := strings.Fields(line)[0]
+		switch section {
+		case "":
+			inv.addHost(group, name, nil)
+		case "children":
+			inv.addChild(group, name)
+		}
+	}
+	return inv
+}
+
+// parseAnsibleScriptInventory parses the output of a dynamic inventory
+// script called with --list argument. A group is either a list of hosts
+// or a dictionary with hosts, vars, and children keys. The variables of
+// the hosts are in _meta.hostvars.
+func parseAnsibleScriptInventory(data []byte) (*ansibleInventory, error) {
+	items := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("error parsing dynamic inventory: %s", err)
+	}
+	meta := &struct {
+		HostVars map[string]map[string]interface{} `json:"hostvars"`
+	}{}
+	if raw, exists := items["_meta"]; exists {
+		if err := json.Unmarshal(raw, meta); err != nil {
+			return nil, fmt.Errorf("error parsing dynamic inventory _meta: %s", err)
+		}
+		delete(items, "_meta")
+	}
+	inv := newAnsibleInventory()
+	for name, raw := range items {
+		g := &struct {
+			Hosts    []string               `json:"hosts"`
+			Vars     map[string]interface{} `json:"vars"`
+			Children []string               `json:"children"`
+		}{}
+		if err := json.Unmarshal(raw, &g.Hosts); err != nil {
+			g.Hosts = nil
+			if err := json.Unmarshal(raw, g); err != nil {
+				return nil, fmt.Errorf("error parsing dynamic inventory group %s: %s", name, err)
+			}
+		}
+		setAnsibleVariables(inv.getGroup(name).vars, g.Vars)
+		for _, host := range g.Hosts {
+			inv.addHost(name, host, meta.HostVars[host])
+		}
+		for _, child := range g.Children {
+			inv.addChild(name, child)
+		}
+	}
+	// the hosts with variables, but without groups, belong to all group
+	for host, vars := range meta.HostVars {
+		if _, exists := inv.hosts[host]; !exists {
+			inv.addHost("all", host, vars)
+		}
+	}
+	return inv, nil
+}
+
+// getParents returns the ancestors of each group.
+func (inv *ansibleInventory) getParents() map[string][]string {
+	parents := make(map[string][]string)
+	for name, g := range inv.groups {
+		for _, child := range g.children {
+			parents[child] = append(parents[child], name)
+		}
+	}
+	return parents
+}
+
+// getHostGroups returns the groups of a host, including the ancestors of
+// the groups, ordered by their depth and then by their names. The groups
+// closer to the host come last, because their variables take precedence.
+func (inv *ansibleInventory) getHostGroups(host string, parents map[string][]string) []string {
+	depths := make(map[string]int)
+	var visit func(name string, depth int)
+	visit = func(name string, depth int) {
+		if d, exists := depths[name]; exists && d >= depth {
+			return
+		}
+		depths[name] = depth
+		for _, p := range parents[name] {
+			visit(p, depth+1)
+		}
+	}
+	for name, g := range inv.groups {
+		for _, h := range g.hosts {
+			if h == host {
+				visit(name, 0)
+			}
+		}
+	}
+	groups := []string{}
+	for name := range depths {
+		if name == "all" || name == "ungrouped" {
+			continue
+		}
+		groups = append(groups, name)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if depths[groups[i]] != depths[groups[j]] {
+			return depths[groups[i]] > depths[groups[j]]
+		}
+		return groups[i] < groups[j]
+	})
+	return groups
+}
+
+// getHosts returns the hosts of the inventory with their variables. The
+// variables of all group come first, then the variables of the groups,
+// then the variables of the host. The variables in group_vars and
+// host_vars directories take precedence over the ones in the inventory.
+func (inv *ansibleInventory) getHosts(varsDir string) ([]*inventoryHost, error) {
+	parents := inv.getParents()
+	names := []string{}
+	for name := range inv.hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hosts := []*inventoryHost{}
+	for _, name := range names {
+		h := &inventoryHost{
+			Name:      name,
+			Variables: make(map[string]string),
+			Groups:    inv.getHostGroups(name, parents),
+		}
+		for _, group := range append([]string{"all"}, h.Groups...) {
+			if g, exists := inv.groups[group]; exists {
+				for k, v := range g.vars {
+					h.Variables[k] = v
+				}
+			}
+			if err := loadAnsibleVarsFiles(h.Variables, varsDir, "group_vars", group); err != nil {
+				return nil, err
+			}
+		}
+		for k, v := range inv.hosts[name] {
+			h.Variables[k] = v
+		}
+		if err := loadAnsibleVarsFiles(h.Variables, varsDir, "host_vars", name); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts, nil
+}
+
+// loadAnsibleVarsFiles reads the variables of a group or a host from
+// group_vars or host_vars directory. The variables are either in a file,
+// e.g. group_vars/cisco.yml, or in the files of a directory, e.g.
+// group_vars/cisco/*.yml. The files encrypted by Ansible Vault are skipped.
+func loadAnsibleVarsFiles(dst map[string]string, dir, kind, name string) error {
+	if dir == "" {
+		return nil
+	}
+	base := filepath.Join(dir, kind, name)
+	files := []string{}
+	for _, ext := range []string{"", ".yml", ".yaml", ".json"} {
+		fi, err := os.Stat(base + ext)
+		if err != nil {
+			continue
+		}
+		if !fi.IsDir() {
+			files = append(files, base+ext)
+			continue
+		}
+		items, err := ioutil.ReadDir(base + ext)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %s", base+ext, err)
+		}
+		for _, item := range items {
+			if item.IsDir() || strings.HasPrefix(item.Name(), ".") {
+				continue
+			}
+			files = append(files, filepath.Join(base+ext, item.Name()))
+		}
+	}
+	for _, fp := range files {
+		data, err := ioutil.ReadFile(fp)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %s", fp, err)
+		}
+		if bytes.HasPrefix(data, []byte("$ANSIBLE_VAULT")) {
+			log.Debugf("the variables file '%s' was skipped because it is encrypted", fp)
+			continue
+		}
+		vars := make(map[string]interface{})
+		if err := yaml.Unmarshal(data, &vars); err != nil {
+			return fmt.Errorf("error parsing %s: %s", fp, err)
+		}
+		setAnsibleVariables(dst, vars)
+	}
+	return nil
+}
+
+// isAnsibleInventoryScript checks whether an inventory file is a dynamic
+// inventory script, i.e. an executable file.
+func isAnsibleInventoryScript(fp string) bool {
+	fi, err := os.Stat(fp)
+	if err != nil {
+		return false
+	}
+	return fi.Mode().IsRegular() && fi.Mode()&0111 != 0
+}
+
+// runAnsibleInventoryScript runs a dynamic inventory script with --list
+// argument.
+func runAnsibleInventoryScript(fp string, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, fp, "--list")
+	cmd.Stderr = &stderr
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running dynamic inventory %s: %s: %s", fp, err, strings.TrimSpace(stderr.String()))
+	}
+	return data, nil
+}
+
+// ansibleNetworkOS are the exporter modules for the values of
+// ansible_network_os variable, without the collection prefix, e.g.
+// cisco.nxos.nxos.
+var ansibleNetworkOS = map[string]string{
+	"nxos": "cisco_nxos",
+	"eos":  "arista_eos",
+}
+
+// getAnsibleVariable returns the value of the first variable set.
+func getAnsibleVariable(vars map[string]string, keys ...string) (string, bool) {
+	for _, k := range keys {
+		if v, exists := vars[k]; exists && v != "" {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// resolveAnsibleVariables returns the variables of a host with the
+// exporter variables derived from the standard Ansible connection
+// variables. The exporter variables take precedence, i.e. os over
+// ansible_network_os, host_overwrite over ansible_host, and api_port and
+// api_proto over ansible_httpapi_port, ansible_port, and
+// ansible_httpapi_use_ssl. The ansible_port is the port of NX-API only
+// when ansible_connection is httpapi, because it is SSH port otherwise.
+func resolveAnsibleVariables(vars map[string]string) map[string]string {
+	resolved := make(map[string]string)
+	for k, v := range vars {
+		resolved[k] = v
+	}
+	if _, exists := vars["os"]; !exists {
+		if v, exists := getAnsibleVariable(vars, "ansible_network_os"); exists {
+			parts := strings.Split(v, ".")
+			v = parts[len(parts)-1]
+			if module, exists := ansibleNetworkOS[v]; exists {
+				v = module
+			}
+			resolved["os"] = v
+		}
+	}
+	if _, exists := vars["host_overwrite"]; !exists {
+		if v, exists := getAnsibleVariable(vars, "ansible_host"); exists {
+			resolved["host_overwrite"] = v
+		}
+	}
+	conn, _ := getAnsibleVariable(vars, "ansible_connection")
+	isHTTPAPI := conn == "httpapi" || conn == "ansible.netcommon.httpapi"
+	if _, exists := vars["api_port"]; !exists {
+		if v, exists := getAnsibleVariable(vars, "ansible_httpapi_port"); exists {
+			resolved["api_port"] = v
+		} else if v, exists := getAnsibleVariable(vars, "ansible_port"); exists && isHTTPAPI {
+			resolved["api_port"] = v
+		}
+	}
+	if _, exists := vars["api_proto"]; !exists && isHTTPAPI {
+		if v, exists := getAnsibleVariable(vars, "ansible_httpapi_use_ssl"); exists {
+			if isAnsibleTrue(v) {
+				resolved["api_proto"] = "https"
+			} else {
+				resolved["api_proto"] = "http"
+			}
+		}
+	}
+	return resolved
+}
+
+// isAnsibleTrue checks whether a variable value is true, e.g. yes, on,
+// or true.
+func isAnsibleTrue(v string) bool {
+	switch strings.ToLower(v) {
+	case "yes", "on", "true", "1", "y", "t":
+		return true
+	}
+	return false
+}
+
+// getAnsibleCredential returns the credential of a host set by
+// ansible_user and ansible_password variables. The password encrypted
+// with Ansible Vault is not supported. The ansible_become_password
+// variable is ignored, because NX-API has no privileged mode.
+func getAnsibleCredential(vars map[string]string) *credentialEntry {
+	username, exists := getAnsibleVariable(vars, "ansible_user", "ansible_ssh_user")
+	if !exists {
+		return nil
+	}
+	password, exists := getAnsibleVariable(vars, "ansible_password", "ansible_ssh_pass")
+	if !exists || strings.HasPrefix(password, "$ANSIBLE_VAULT") {
+		return nil
+	}
+	return &credentialEntry{
+		Description: "inventory variables",
+		Username:    username,
+		Password:    password,
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	ansible "github.com/greenpau/go-ansible-db/pkg/db"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestAnsibleInventory(t *testing.T) {
+	testCases := []struct {
+		name      string
+		file      string
+		variables map[string]map[string]string
+		groups    map[string][]string
+	}{
+		{
+			name: "yaml inventory with group_vars and host_vars",
+			file: "testdata/ansible/yaml/hosts.yml",
+			variables: map[string]map[string]string{
+				"ny-sw01": {
+					"contact_person": "Paul Greenberg @greenpau",
+					"api_proto":      "http",
+					"os":             "cisco_nxos",
+					"vendor":         "Cisco Systems",
+					"datacenter":     "ny4",
+					"api_port":       "9443",
+					"host_overwrite": "10.1.1.1",
+				},
+				"ny-sw04": {
+					"contact_person": "Paul Greenberg @greenpau",
+					"api_proto":      "http",
+					"os":             "cisco_nxos",
+					"vendor":         "Cisco Systems",
+					"datacenter":     "ny4",
+					"api_port":       "8443",
+				},
+			},
+			groups: map[string][]string{
+				"ny-sw01": {"cisco", "ny4", "ny4-cisco"},
+				"ny-sw04": {"cisco", "ny4", "ny4-cisco"},
+			},
+		},
+		{
+			name: "dynamic inventory script with group_vars",
+			file: "testdata/ansible/script/inventory.sh",
+			variables: map[string]map[string]string{
+				"ny-sw01": {
+					"os":             "cisco_nxos",
+					"api_proto":      "https",
+					"datacenter":     "ny4",
+					"host_overwrite": "10.1.1.1",
+					"api_port":       "8443",
+				},
+				"ny-sw04": {
+					"os":             "cisco_nxos",
+					"api_proto":      "https",
+					"datacenter":     "ny4",
+					"host_overwrite": "10.1.1.4",
+					"lab":            "true",
+				},
+			},
+			groups: map[string][]string{
+				"ny-sw01": {"cisco", "ny4-cisco"},
+				"ny-sw04": {"cisco", "lab", "ny4-cisco"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		p := &ansibleInventoryProvider{file: tc.file}
+		hosts, err := p.getHosts()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.name, err)
+		}
+		if len(hosts) != len(tc.variables) {
+			t.Fatalf("%s: expected %d hosts, but got %d", tc.name, len(tc.variables), len(hosts))
+		}
+		for _, h := range hosts {
+			if !reflect.DeepEqual(h.Variables, tc.variables[h.Name]) {
+				t.Errorf("%s: expected %s variables %v, but got %v", tc.name, h.Name, tc.variables[h.Name], h.Variables)
+			}
+			if !reflect.DeepEqual(h.Groups, tc.groups[h.Name]) {
+				t.Errorf("%s: expected %s groups %v, but got %v", tc.name, h.Name, tc.groups[h.Name], h.Groups)
+			}
+		}
+	}
+}
+
+func TestAnsibleINIInventory(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/ansible/ini/hosts")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// the hosts as the INI inventory parser returns them, with the
+	// variables of the groups merged
+	items := []*ansible.InventoryHost{
+		{
+			Name:      "ny-sw01",
+			Groups:    []string{"access", "region-east", "zone-ny4"},
+			Variables: map[string]string{"host_overwrite": "10.1.1.1", "datacenter": "ny4"},
+		},
+		{
+			Name:      "ny-sw02",
+			Groups:    []string{"access", "region-east", "zone-ny4"},
+			Variables: map[string]string{"datacenter": "ny4", "api_port": "10443"},
+		},
+	}
+	hosts, err := getAnsibleINIHosts(data, items, "testdata/ansible/ini")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]map[string]string{
+		"ny-sw01": {
+			"api_proto":      "http",
+			"api_port":       "9443",
+			"snmp_location":  "ny4",
+			"region":         "east",
+			"datacenter":     "ny4",
+			"host_overwrite": "10.1.1.1",
+		},
+		"ny-sw02": {
+			"api_proto":     "http",
+			"api_port":      "10443",
+			"snmp_location": "ny4",
+			"region":        "east",
+			"datacenter":    "ny4",
+		},
+	}
+	if len(hosts) != len(expected) {
+		t.Fatalf("expected %d hosts, but got %d", len(expected), len(hosts))
+	}
+	for _, h := range hosts {
+		if !reflect.DeepEqual(h.Variables, expected[h.Name]) {
+			t.Errorf("expected %s variables %v, but got %v", h.Name, expected[h.Name], h.Variables)
+		}
+	}
+}
+
+func TestResolveAnsibleVariables(t *testing.T) {
+	testCases := []struct {
+		name     string
+		vars     map[string]string
+		expected map[string]string
+	}{
+		{
+			name: "httpapi connection",
+			vars: map[string]string{
+				"ansible_network_os":      "cisco.nxos.nxos",
+				"ansible_host":            "10.1.1.1",
+				"ansible_connection":      "ansible.netcommon.httpapi",
+				"ansible_port":            "8443",
+				"ansible_httpapi_use_ssl": "yes",
+			},
+			expected: map[string]string{"os": "cisco_nxos", "host_overwrite": "10.1.1.1", "api_port": "8443", "api_proto": "https"},
+		},
+		{
+			name: "ssh port is not api port",
+			vars: map[string]string{
+				"ansible_network_os": "eos",
+				"ansible_connection": "network_cli",
+				"ansible_port":       "22",
+			},
+			expected: map[string]string{"os": "arista_eos"},
+		},
+		{
+			name: "exporter variables take precedence",
+			vars: map[string]string{
+				"os":                   "cisco_nxos",
+				"ansible_network_os":   "eos",
+				"host_overwrite":       "localhost",
+				"ansible_host":         "10.1.1.1",
+				"api_port":             "8224",
+				"ansible_httpapi_port": "443",
+			},
+			expected: map[string]string{"os": "cisco_nxos", "host_overwrite": "localhost", "api_port": "8224"},
+		},
+	}
+	for _, tc := range testCases {
+		resolved := resolveAnsibleVariables(tc.vars)
+		for _, k := range []string{"os", "host_overwrite", "api_port", "api_proto"} {
+			if resolved[k] != tc.expected[k] {
+				t.Errorf("%s: expected %s %q, but got %q", tc.name, k, tc.expected[k], resolved[k])
+			}
+		}
+	}
+
+	n := &NetworkNode{Name: "ny-sw01", Variables: map[string]string{
+		"ansible_user":            "netops",
+		"ansible_password":        "secret",
+		"ansible_become_password": "enable",
+	}}
+	vault := &staticCredentialProvider{entries: []*credentialEntry{
+		{Default: true, Priority: 100, Username: "admin", Password: "cisco", Description: "default"},
+	}}
+	if err := n.setCredentials([]credentialProvider{vault}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(n.credentials) != 2 || n.credentials[0].Username != "netops" || n.credentials[1].Username != "admin" {
+		t.Errorf("expected the inventory credential to precede the vault credential")
+	}
+	if n.credentials[0].Password != "secret" {
+		t.Errorf("expected ansible_password to be the password, but got %q", n.credentials[0].Password)
+	}
+}
@@ -30,14 +30,43 @@ func (e *Exporter) AddAuthenticationToken(s string) error {
 	return nil
 }
 
-func (e *Exporter) authorize(r *http.Request) (string, bool) {
+// AddAuthenticationSubject adds the subject of a client certificate
+// allowed to access the exporter itself. The subject is either
+// the common name, e.g. prometheus.example.com, or the distinguished
+// name, e.g. CN=prometheus.example.com,O=Example. The subject has full
+// access, same as the -auth.token tokens. The subjects with a narrower
+// scope are listed in the token file.
+func (e *Exporter) AddAuthenticationSubject(s string) error {
+	if s == "" {
+		return fmt.Errorf("invalid empty subject")
+	}
+	e.Subjects[s] = true
+	return nil
+}
+
+// authorize returns the token and the scope of a requester. The token
+// is empty when the requester was authorized by a client certificate.
+func (e *Exporter) authorize(r *http.Request) (string, *authScope, bool) {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		subject := r.TLS.VerifiedChains[0][0].Subject
+		if e.Subjects[subject.CommonName] || e.Subjects[subject.String()] {
+			return "", fullScope, true
+		}
+		if e.tokenStore != nil {
+			if scope, exists := e.tokenStore.lookupSubject(subject); exists {
+				return "", scope, true
+			}
+		}
+		log.Debugf("client certificate subject %q from %q is not authorized", subject.String(), r.RemoteAddr)
+	}
+
 	var invalidToken bool
 	tokens := []string{"x_token", "x-token", "X-Token"}
 	for _, t := range tokens {
 		token := r.Header.Get(t)
 		if token != "" {
-			if _, exists := e.Tokens[token]; exists {
-				return token, true
+			if scope, exists := e.getTokenScope(token); exists {
+				return token, scope, true
 			}
 			invalidToken = true
 		}
@@ -46,8 +75,8 @@ func (e *Exporter) authorize(r *http.Request) (string, bool) {
 	for _, t := range tokens {
 		token := r.URL.Query().Get(t)
 		if token != "" {
-			if _, exists := e.Tokens[token]; exists {
-				return token, true
+			if scope, exists := e.getTokenScope(token); exists {
+				return token, scope, true
 			}
 			invalidToken = true
 		}
@@ -59,5 +88,17 @@ func (e *Exporter) authorize(r *http.Request) (string, bool) {
 		log.Warnf("unauthorized access from %q due to the lack of auth token", r.RemoteAddr)
 	}
 
-	return "", false
+	return "", nil, false
+}
+
+// getTokenScope returns the scope of a token passed via -auth.token
+// argument or found in the token file.
+func (e *Exporter) getTokenScope(token string) (*authScope, bool) {
+	if _, exists := e.Tokens[token]; exists {
+		return fullScope, true
+	}
+	if e.tokenStore != nil {
+		return e.tokenStore.lookup(token)
+	}
+	return nil, false
 }
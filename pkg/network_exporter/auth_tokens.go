@@ -0,0 +1,205 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+// authScope is what a requester is allowed to access. The nodes and
+// groups are the inventory hosts and groups the requester may scrape.
+type authScope struct {
+	name    string
+	all     bool
+	nodes   map[string]bool
+	groups  map[string]bool
+	summary bool
+	admin   bool
+}
+
+// fullScope is the scope of the tokens passed via -auth.token argument
+// and of the authorized client certificate subjects.
+var fullScope = &authScope{
+	name:    "default",
+	all:     true,
+	summary: true,
+	admin:   true,
+}
+
+// allowsNode checks whether the scope allows scraping a network node.
+func (s *authScope) allowsNode(n *NetworkNode) bool {
+	if s == nil {
+		return false
+	}
+	if s.all || s.nodes[n.Name] {
+		return true
+	}
+	for _, g := range n.groups {
+		if s.groups[g] {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenFileEntry is an entry in the token file. The hash is either
+// a bcrypt hash, e.g. $2a$10$..., or a hex-encoded SHA256 hash prefixed
+// with sha256:, e.g. sha256:9f86d0.... The entries in the subjects
+// section have a client certificate subject instead of a hash.
+type tokenFileEntry struct {
+	Name    string   `yaml:"name"`
+	Hash    string   `yaml:"hash"`
+	Subject string   `yaml:"subject"`
+	Nodes   []string `yaml:"nodes"`
+	Groups  []string `yaml:"groups"`
+	Summary bool     `yaml:"summary"`
+	Admin   bool     `yaml:"admin"`
+}
+
+type tokenFile struct {
+	Tokens   []*tokenFileEntry `yaml:"tokens"`
+	Subjects []*tokenFileEntry `yaml:"subjects"`
+}
+
+type hashedToken struct {
+	hash  string
+	scope *authScope
+}
+
+// tokenStore holds hashed authentication tokens and the scopes of
+// client certificate subjects. Since bcrypt comparisons are slow by
+// design, the store remembers the SHA256 digests of the tokens it already
+// verified.
+type tokenStore struct {
+	sync.RWMutex
+	tokens   []*hashedToken
+	verified map[string]*authScope
+	subjects map[string]*authScope
+}
+
+func newTokenStore() *tokenStore {
+	return &tokenStore{
+		tokens:   []*hashedToken{},
+		verified: make(map[string]*authScope),
+		subjects: make(map[string]*authScope),
+	}
+}
+
+func newAuthScope(entry *tokenFileEntry) *authScope {
+	scope := &authScope{
+		name:    entry.Name,
+		nodes:   make(map[string]bool),
+		groups:  make(map[string]bool),
+		summary: entry.Summary,
+		admin:   entry.Admin,
+	}
+	for _, v := range entry.Nodes {
+		if v == "*" {
+			scope.all = true
+		}
+		scope.nodes[v] = true
+	}
+	for _, v := range entry.Groups {
+		if v == "all" {
+			scope.all = true
+		}
+		scope.groups[v] = true
+	}
+	return scope
+}
+
+// LoadAuthenticationTokens loads hashed authentication tokens and their
+// scopes from a YAML file.
+func (e *Exporter) LoadAuthenticationTokens(fp string) error {
+	data, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return fmt.Errorf("error reading token file: %s", err)
+	}
+	tf := &tokenFile{}
+	if err := yaml.Unmarshal(data, tf); err != nil {
+		return fmt.Errorf("error parsing token file %s: %s", fp, err)
+	}
+	store := newTokenStore()
+	for i, entry := range tf.Tokens {
+		if entry.Name == "" {
+			entry.Name = fmt.Sprintf("token%d", i+1)
+		}
+		if !strings.HasPrefix(entry.Hash, "$2") && !strings.HasPrefix(entry.Hash, "sha256:") {
+			return fmt.Errorf("token %s has unsupported hash, expected bcrypt or sha256:<hex>", entry.Name)
+		}
+		store.tokens = append(store.tokens, &hashedToken{
+			hash:  entry.Hash,
+			scope: newAuthScope(entry),
+		})
+	}
+	for i, entry := range tf.Subjects {
+		if entry.Subject == "" {
+			return fmt.Errorf("subject entry %d has no subject", i+1)
+		}
+		if entry.Name == "" {
+			entry.Name = entry.Subject
+		}
+		store.subjects[entry.Subject] = newAuthScope(entry)
+	}
+	e.tokenStore = store
+	return nil
+}
+
+// lookup returns the scope of a token, if the token matches one of
+// the hashes in the store.
+func (ts *tokenStore) lookup(token string) (*authScope, bool) {
+	digest := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(digest[:])
+	ts.RLock()
+	scope, exists := ts.verified[key]
+	ts.RUnlock()
+	if exists {
+		return scope, true
+	}
+	for _, t := range ts.tokens {
+		if strings.HasPrefix(t.hash, "sha256:") {
+			expected := strings.ToLower(strings.TrimPrefix(t.hash, "sha256:"))
+			if subtle.ConstantTimeCompare([]byte(expected), []byte(key)) != 1 {
+				continue
+			}
+		} else if bcrypt.CompareHashAndPassword([]byte(t.hash), []byte(token)) != nil {
+			continue
+		}
+		ts.Lock()
+		ts.verified[key] = t.scope
+		ts.Unlock()
+		return t.scope, true
+	}
+	return nil, false
+}
+
+// lookupSubject returns the scope of a client certificate subject, by
+// its common name or its distinguished name.
+func (ts *tokenStore) lookupSubject(subject pkix.Name) (*authScope, bool) {
+	if scope, exists := ts.subjects[subject.CommonName]; exists {
+		return scope, true
+	}
+	scope, exists := ts.subjects[subject.String()]
+	return scope, exists
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"golang.org/x/crypto/bcrypt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthenticationTokenFile(t *testing.T) {
+	tenantDigest := sha256.Sum256([]byte("tenant-secret"))
+	nocHash, err := bcrypt.GenerateFromPassword([]byte("noc-secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	dir, err := ioutil.TempDir("", "network-exporter")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	fp := filepath.Join(dir, "tokens.yml")
+	data := "---\ntokens:\n" +
+		"- name: tenant\n  hash: sha256:" + hex.EncodeToString(tenantDigest[:]) + "\n  groups: [ny4-cisco]\n" +
+		"- name: noc\n  hash: '" + string(nocHash) + "'\n  nodes: ['*']\n  summary: true\n  admin: true\n"
+	if err := ioutil.WriteFile(fp, []byte(data), 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	e := &Exporter{Tokens: make(map[string]bool)}
+	if err := e.LoadAuthenticationTokens(fp); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sw01 := &NetworkNode{Name: "ny-sw01", groups: []string{"ny4-cisco", "ny4"}}
+	sw03 := &NetworkNode{Name: "ny-sw03", groups: []string{"ny5-cisco", "ny5"}}
+
+	_, scope, authorized := e.authorize(httptest.NewRequest("GET", "/metrics?x-token=tenant-secret", nil))
+	if !authorized {
+		t.Fatalf("expected tenant token to be authorized")
+	}
+	if !scope.allowsNode(sw01) || scope.allowsNode(sw03) || scope.summary || scope.admin {
+		t.Errorf("unexpected tenant token scope: %+v", scope)
+	}
+
+	_, scope, authorized = e.authorize(httptest.NewRequest("GET", "/metrics?x-token=noc-secret", nil))
+	if !authorized {
+		t.Fatalf("expected noc token to be authorized")
+	}
+	if !scope.allowsNode(sw01) || !scope.allowsNode(sw03) || !scope.summary || !scope.admin {
+		t.Errorf("unexpected noc token scope: %+v", scope)
+	}
+
+	if _, _, authorized := e.authorize(httptest.NewRequest("GET", "/metrics?x-token=anonymous", nil)); authorized {
+		t.Errorf("expected unknown token not to be authorized")
+	}
+}
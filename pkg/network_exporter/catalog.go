@@ -0,0 +1,104 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v2"
+	"sort"
+)
+
+const (
+	// CatalogFormatMarkdown is the format of the metric catalog as
+	// a markdown table.
+	CatalogFormatMarkdown = "markdown"
+	// CatalogFormatJSON is the format of the metric catalog as JSON.
+	CatalogFormatJSON = "json"
+	// CatalogFormatYAML is the format of the metric catalog as YAML.
+	CatalogFormatYAML = "yaml"
+)
+
+// MetricInfo is the description of a metric in the metric catalog.
+type MetricInfo struct {
+	Name      string   `json:"name" yaml:"name"`
+	Help      string   `json:"help" yaml:"help"`
+	Type      string   `json:"type" yaml:"type"`
+	Unit      string   `json:"unit,omitempty" yaml:"unit,omitempty"`
+	Labels    []string `json:"labels" yaml:"labels"`
+	Subsystem string   `json:"subsystem" yaml:"subsystem"`
+	Modules   []string `json:"modules" yaml:"modules"`
+}
+
+// getValueTypeName returns the name of the type of a metric.
+func getValueTypeName(t prometheus.ValueType) string {
+	switch t {
+	case prometheus.CounterValue:
+		return "counter"
+	case prometheus.GaugeValue:
+		return "gauge"
+	}
+	return "untyped"
+}
+
+// getMetricInfos returns the metric catalog. The labels of the metrics
+// match the label strategy and the interface description parser of
+// a network node.
+func (n *NetworkNode) getMetricInfos() []*MetricInfo {
+	metrics := []*MetricInfo{}
+	for _, info := range metricCatalog {
+		labels := info.labels
+		if info.desc == ifaceDescriptionInfo && n.descrParser != nil {
+			labels = n.descrParser.info.labels
+		}
+		labels = append([]string{}, getLabelNames(labels, n.labelStrategy)...)
+		sort.Strings(labels)
+		metrics = append(metrics, &MetricInfo{
+			Name:      info.fqName,
+			Help:      info.help,
+			Type:      getValueTypeName(info.valueType),
+			Unit:      info.unit,
+			Labels:    labels,
+			Subsystem: info.collector,
+			Modules:   info.modules,
+		})
+	}
+	return metrics
+}
+
+// GetMetricCatalog returns the metric catalog in markdown, JSON, or YAML
+// format.
+func (n *NetworkNode) GetMetricCatalog(format string) (string, error) {
+	switch format {
+	case CatalogFormatMarkdown, "":
+		return n.GetMetricsTable(), nil
+	case CatalogFormatJSON:
+		data, err := json.MarshalIndent(map[string]interface{}{"metrics": n.getMetricInfos()}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error rendering metric catalog: %s", err)
+		}
+		return string(data) + "\n", nil
+	case CatalogFormatYAML:
+		data, err := yaml.Marshal(map[string]interface{}{"metrics": n.getMetricInfos()})
+		if err != nil {
+			return "", fmt.Errorf("error rendering metric catalog: %s", err)
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("unsupported metric catalog format %q, supported: %s, %s, %s",
+		format, CatalogFormatMarkdown, CatalogFormatJSON, CatalogFormatYAML,
+	)
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	simulator "github.com/greenpau/network_exporter/pkg/nxapi_sim"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"gopkg.in/yaml.v2"
+	"testing"
+)
+
+func TestMetricCatalogCoversCollectors(t *testing.T) {
+	ts, err := simulator.NewTestServer(simulator.Options{Hostname: "ny-sw01"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer ts.Close()
+
+	for _, strategy := range []string{LabelStrategyUUID, LabelStrategyName, LabelStrategyBoth} {
+		e := newSimulatedExporter(t, ts)
+		n := e.Nodes["ny-sw01"]
+		n.labelStrategy = strategy
+		n.enabledSubsystems = nil
+		n.ifaceRates = true
+		n.descrParser, err = newDescriptionParser([]string{"role"}, "", ";")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		catalog := make(map[*prometheus.Desc]*descInfo)
+		for _, info := range metricCatalog {
+			if desc := n.getCatalogDesc(info); desc != nil {
+				catalog[desc] = info
+			}
+		}
+		// the interface rates are produced by the second poll
+		ch := make(chan prometheus.Metric, 100000)
+		n.Collect(make(chan prometheus.Metric, 100000))
+		n.nextCollectionTicker = 0
+		n.Collect(ch)
+		close(ch)
+		if len(ch) == 0 {
+			t.Fatalf("%s: expected metrics, but got none", strategy)
+		}
+		for metric := range ch {
+			info, exists := catalog[metric.Desc()]
+			if !exists {
+				t.Errorf("%s: metric %s is not in the catalog", strategy, metric.Desc())
+				continue
+			}
+			m := &dto.Metric{}
+			if err := metric.Write(m); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var valueType prometheus.ValueType
+			switch {
+			case m.Counter != nil:
+				valueType = prometheus.CounterValue
+			case m.Gauge != nil:
+				valueType = prometheus.GaugeValue
+			default:
+				valueType = prometheus.UntypedValue
+			}
+			if valueType != info.valueType {
+				t.Errorf("%s: metric %s is %s, but the catalog has %s", strategy, info.fqName,
+					getValueTypeName(valueType), getValueTypeName(info.valueType))
+			}
+		}
+	}
+}
+
+func TestGetMetricCatalog(t *testing.T) {
+	n := &NetworkNode{labelStrategy: LabelStrategyBoth}
+	for _, format := range []string{CatalogFormatJSON, CatalogFormatYAML} {
+		out, err := n.GetMetricCatalog(format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", format, err)
+		}
+		catalog := struct {
+			Metrics []*MetricInfo `json:"metrics" yaml:"metrics"`
+		}{}
+		if format == CatalogFormatJSON {
+			err = json.Unmarshal([]byte(out), &catalog)
+		} else {
+			err = yaml.UnmarshalStrict([]byte(out), &catalog)
+		}
+		if err != nil {
+			t.Fatalf("%s: error parsing catalog: %s", format, err)
+		}
+		if len(catalog.Metrics) != len(metricCatalog) {
+			t.Fatalf("%s: expected %d metrics, but got %d", format, len(metricCatalog), len(catalog.Metrics))
+		}
+		m := catalog.Metrics[0]
+		if m.Name != "net_node_up" || m.Type != "gauge" || m.Subsystem != "system" ||
+			len(m.Labels) != 2 || m.Labels[1] != "node_name" || len(m.Modules) == 0 {
+			t.Errorf("%s: unexpected catalog entry %+v", format, m)
+		}
+	}
+	if _, err := n.GetMetricCatalog("csv"); err == nil {
+		t.Errorf("expected error with unsupported format, but got none")
+	}
+}
@@ -16,6 +16,7 @@ package exporter
 
 import (
 	//"github.com/davecgh/go-spew/spew"
+	"fmt"
 	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
@@ -26,8 +27,10 @@ import (
 // GatherMetrics collect data from a network node and stores them
 // as Prometheus metrics.
 func (n *NetworkNode) GatherMetrics() {
+	lockStart := time.Now()
 	n.Lock()
 	defer n.Unlock()
+	exporterLockWaitDuration.WithLabelValues(n.Name).Observe(time.Since(lockStart).Seconds())
 	log.Debugf("%s: GatherMetrics() locked for %s", n.UUID, n.Name)
 	if time.Now().Unix() < n.nextCollectionTicker {
 		return
@@ -40,31 +43,48 @@ func (n *NetworkNode) GatherMetrics() {
 	upValue := 1
 
 	cli := api.NewClient()
-	cli.SetHost(n.target)
-	if n.port != 0 {
-		cli.SetPort(n.port)
+	var lastErr error
+	credentials := n.credentials
+	if n.replayDir != "" && len(credentials) == 0 {
+		credentials = []*credential{{Description: "replay"}}
 	}
-	if n.proto != "" {
-		cli.SetProtocol(n.proto)
+	dc, err := n.getDeviceClient(cli)
+	if err != nil {
+		log.Debugf("%s: getDeviceClient() failed (host: %s, target: %s): %s", n.UUID, n.Name, n.target, err)
+		lastErr = err
+		credentials = nil
+	} else {
+		defer dc.Close()
 	}
 
 	var info *api.SysInfo
 	var workingCredential *credential
+	attempts := []*credentialAttempt{}
 	// test all available credentials
 	tryFailed := false
 	failedCredentials := make(map[int]bool)
 	for {
-		for i, c := range n.credentials {
+		for i, c := range credentials {
 			if c.Failed && !tryFailed {
 				continue
 			}
 			if _, exists := failedCredentials[i]; exists {
 				continue
 			}
-			cli.SetUsername(c.Username)
-			cli.SetPassword(c.Password)
-			data, err := cli.GetSystemInfo()
+			dc.setCredential(c.Username, c.Password)
+			sysInfoStart := time.Now()
+			data, err := dc.GetSystemInfo()
+			n.observeAPIRequest("show version", sysInfoStart, err)
+			attempt := &credentialAttempt{
+				Description: c.Description,
+				Username:    c.Username,
+				Result:      "success",
+			}
+			attempts = append(attempts, attempt)
 			if err != nil {
+				attempt.Result = "failure"
+				attempt.Error = err.Error()
+				lastErr = err
 				failedCredentials[i] = true
 				log.Debugf("%s: GetSystemInfo() failed (host: %s, target: %s, username: %s): %s", n.UUID, n.Name, n.target, c.Username, err)
 				c.Failed = true
@@ -85,20 +105,29 @@ func (n *NetworkNode) GatherMetrics() {
 		break
 	}
 
+	n.setLastDuration("system", time.Since(start))
+
 	if workingCredential == nil || info == nil {
 		n.IncrementErrorCounter()
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no credentials available")
+		}
+		n.setLastError("system", lastErr)
+		n.credentialDescription = ""
 		upValue = 0
 	} else {
+		n.setLastError("system", nil)
+		n.credentialDescription = workingCredential.Description
 		log.Debugf("%s: hostname: %s, chassis id: %s", n.UUID, info.Hostname, info.ChassisID)
 		// General Metrics
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			nodeSystemHostname,
 			prometheus.GaugeValue,
 			1,
 			n.UUID,
 			info.Hostname,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			nodeSystemIdentifier,
 			prometheus.GaugeValue,
 			1,
@@ -106,69 +135,60 @@ func (n *NetworkNode) GatherMetrics() {
 			info.ProcessorBoardID,
 		))
 
-		var wg sync.WaitGroup
-		wg.Add(6)
-
-		go func() {
-			defer wg.Done()
-			n.GetInterfaces(cli)
-		}()
-
-		go func() {
-			defer wg.Done()
-			n.GetVlans(cli)
-		}()
-
-		go func() {
-			defer wg.Done()
-			n.GetSystemEnvironment(cli)
-		}()
-
-		go func() {
-			defer wg.Done()
-			n.GetSystemResources(cli)
-		}()
-
-		go func() {
-			defer wg.Done()
-			n.GetTransceivers(cli)
-		}()
-
-		go func() {
-			defer wg.Done()
-			n.GetRoutingBgp(cli)
-		}()
+		collectors := []struct {
+			subsystem string
+			collect   func(DeviceClient)
+		}{
+			{"interfaces", n.GetInterfaces},
+			{"vlans", n.GetVlans},
+			{"environment", n.GetSystemEnvironment},
+			{"resources", n.GetSystemResources},
+			{"transceivers", n.GetTransceivers},
+			{"bgp", n.GetRoutingBgp},
+		}
 
+		var wg sync.WaitGroup
+		for _, c := range collectors {
+			if !n.isSubsystemEnabled(c.subsystem) {
+				continue
+			}
+			wg.Add(1)
+			go func(subsystem string, collect func(DeviceClient)) {
+				defer wg.Done()
+				defer n.observeCollection(subsystem, time.Now())
+				collect(dc)
+			}(c.subsystem, c.collect)
+		}
 		wg.Wait()
 	}
 
 	// Generic Metrics
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		nodeUp,
 		prometheus.GaugeValue,
 		float64(upValue),
 		n.UUID,
 	))
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		nodeHostname,
 		prometheus.GaugeValue,
 		1,
 		n.UUID,
 		n.Name,
 	))
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		nodeErrors,
 		prometheus.CounterValue,
 		float64(n.errors),
 		n.UUID,
 	))
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		nodeNextScrape,
 		prometheus.CounterValue,
 		float64(n.nextCollectionTicker),
 		n.UUID,
 	))
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		nodeScrapeTime,
 		prometheus.GaugeValue,
 		time.Since(start).Seconds(),
@@ -184,6 +204,17 @@ func (n *NetworkNode) GatherMetrics() {
 	}
 	n.timestamp = time.Now().Format(time.RFC3339)
 
+	n.setSeriesCount(n.getSeriesCount())
+	poll := &pollOutcome{
+		Timestamp: start,
+		Result:    n.result,
+		Duration:  time.Since(start),
+	}
+	if lastErr != nil && upValue == 0 {
+		poll.Error = lastErr.Error()
+	}
+	n.addPollOutcome(poll, attempts)
+
 	log.Debugf("%s: GatherMetrics() returns", n.UUID)
 	return
 }
@@ -15,24 +15,158 @@
 package exporter
 
 import (
-	//"fmt"
-	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
-	//"github.com/prometheus/client_golang/prometheus"
-	//"github.com/prometheus/common/log"
-	//"strconv"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+	"strconv"
+	"time"
 )
 
-// GetRoutingBgp collects BGP routing related metrics.
-func (n *NetworkNode) GetRoutingBgp(cli *api.Client) {
-	// TODO
-	return
-	/*
-		bgp, err := cli.GetBgpSummary()
+// bgpSession is a BGP session of a node.
+type bgpSession struct {
+	VRF                string
+	Neighbor           string
+	RemoteAS           string
+	State              string
+	ConnectionsDropped float64
+}
+
+// nxapiValue is a scalar value in an NX-API response. Depending on the
+// NX-OS release, the numbers are either JSON numbers or strings.
+type nxapiValue string
+
+func (v *nxapiValue) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = nxapiValue(s)
+		return nil
+	}
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err != nil {
+		return err
+	}
+	*v = nxapiValue(num.String())
+	return nil
+}
+
+// getNXAPIRows returns the rows of an NX-API table. A table with a
+// single row has the row instead of an array of rows.
+func getNXAPIRows(data json.RawMessage) ([]json.RawMessage, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	if data[0] != '[' {
+		return []json.RawMessage{data}, nil
+	}
+	rows := []json.RawMessage{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseBgpSessions parses the body of show bgp sessions response. The
+// body is empty when the node has no BGP sessions.
+func parseBgpSessions(body json.RawMessage) ([]*bgpSession, error) {
+	sessions := []*bgpSession{}
+	if len(bytes.TrimSpace(body)) == 0 || string(body) == "null" {
+		return sessions, nil
+	}
+	var data struct {
+		Vrfs struct {
+			Rows json.RawMessage `json:"ROW_vrf"`
+		} `json:"TABLE_vrf"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("error parsing show bgp sessions response: %s", err)
+	}
+	vrfs, err := getNXAPIRows(data.Vrfs.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing show bgp sessions response: %s", err)
+	}
+	for _, v := range vrfs {
+		var vrf struct {
+			Name      nxapiValue `json:"vrf-name-out"`
+			Neighbors struct {
+				Rows json.RawMessage `json:"ROW_neighbor"`
+			} `json:"TABLE_neighbor"`
+		}
+		if err := json.Unmarshal(v, &vrf); err != nil {
+			return nil, fmt.Errorf("error parsing show bgp sessions response: %s", err)
+		}
+		neighbors, err := getNXAPIRows(vrf.Neighbors.Rows)
 		if err != nil {
-			log.Debugf("%s: GetRoutingBgp() failed (host: %s, target: %s): %s", n.UUID, n.Name, n.target, err)
-			n.IncrementErrorCounter()
-			return
+			return nil, fmt.Errorf("error parsing show bgp sessions response: %s", err)
+		}
+		for _, nb := range neighbors {
+			var neighbor struct {
+				ID                 nxapiValue `json:"neighbor-id"`
+				RemoteAS           nxapiValue `json:"remoteas"`
+				State              nxapiValue `json:"state"`
+				ConnectionsDropped nxapiValue `json:"connectionsdropped"`
+			}
+			if err := json.Unmarshal(nb, &neighbor); err != nil {
+				return nil, fmt.Errorf("error parsing show bgp sessions response: %s", err)
+			}
+			s := &bgpSession{
+				VRF:      string(vrf.Name),
+				Neighbor: string(neighbor.ID),
+				RemoteAS: string(neighbor.RemoteAS),
+				State:    string(neighbor.State),
+			}
+			if v, err := strconv.ParseFloat(string(neighbor.ConnectionsDropped), 64); err == nil {
+				s.ConnectionsDropped = v
+			}
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
+// GetRoutingBgp collects BGP session related metrics.
+func (n *NetworkNode) GetRoutingBgp(cli DeviceClient) {
+	start := time.Now()
+	sessions, err := cli.GetBgpSessions()
+	if e, ok := err.(*nxapiError); ok && e.isInvalidCommand() {
+		// BGP feature is not enabled on the node
+		log.Debugf("%s: GetRoutingBgp() skipped (host: %s, target: %s): %s", n.UUID, n.Name, n.target, err)
+		n.observeAPIRequest("show bgp sessions", start, nil)
+		n.setLastError("bgp", nil)
+		return
+	}
+	n.observeAPIRequest("show bgp sessions", start, err)
+	if err != nil {
+		log.Debugf("%s: GetRoutingBgp() failed (host: %s, target: %s): %s", n.UUID, n.Name, n.target, err)
+		n.IncrementErrorCounter()
+		n.setLastError("bgp", err)
+		return
+	}
+	n.setLastError("bgp", nil)
+	for _, s := range sessions {
+		// session state: established (1), any other state (0)
+		var _sessionUp float64
+		if s.State == "Established" {
+			_sessionUp = 1
 		}
-		log.Debugf("%s: %v", n.UUID, bgp)
-	*/
+		n.metrics = append(n.metrics, n.newConstMetric(
+			bgpSessionUp,
+			prometheus.GaugeValue,
+			_sessionUp,
+			n.UUID,
+			s.VRF,
+			s.Neighbor,
+			s.RemoteAS,
+		))
+		n.metrics = append(n.metrics, n.newConstMetric(
+			bgpSessionConnectionsDropped,
+			prometheus.CounterValue,
+			s.ConnectionsDropped,
+			n.UUID,
+			s.VRF,
+			s.Neighbor,
+		))
+	}
 }
@@ -0,0 +1,74 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	simulator "github.com/greenpau/network_exporter/pkg/nxapi_sim"
+	"strings"
+	"testing"
+)
+
+func TestBgpSessions(t *testing.T) {
+	ts, err := simulator.NewTestServer(simulator.Options{Hostname: "ny-sw01"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer ts.Close()
+	body := scrapeSimulatedExporter(t, newSimulatedExporter(t, ts), "")
+	expected := map[string]string{
+		`neighbor="10.1.2.1",node=`: "1",
+		`neighbor="10.1.2.5",node=`: "0",
+	}
+	for prefix, value := range expected {
+		found := false
+		for _, line := range strings.Split(body, "\n") {
+			if strings.HasPrefix(line, "net_bgp_session_up{") && strings.Contains(line, prefix) {
+				found = true
+				if !strings.HasSuffix(line, " "+value) {
+					t.Errorf("expected %s, but got %s", value, line)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("expected net_bgp_session_up with %s, but got none", prefix)
+		}
+	}
+	if !strings.Contains(body, "net_bgp_session_connections_dropped{") {
+		t.Errorf("expected net_bgp_session_connections_dropped, but got none")
+	}
+}
+
+func TestParseBgpSessions(t *testing.T) {
+	// a table with a single row has the row instead of an array, and
+	// the numbers may be JSON numbers
+	body := json.RawMessage(`{"TABLE_vrf": {"ROW_vrf": {"vrf-name-out": "blue",
+		"TABLE_neighbor": {"ROW_neighbor": {"neighbor-id": "10.2.0.1", "remoteas": 65010,
+		"state": "Established", "connectionsdropped": 5}}}}}`)
+	sessions, err := parseBgpSessions(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 session, but got %d", len(sessions))
+	}
+	s := sessions[0]
+	if s.VRF != "blue" || s.Neighbor != "10.2.0.1" || s.RemoteAS != "65010" || s.State != "Established" || s.ConnectionsDropped != 5 {
+		t.Errorf("unexpected session %+v", s)
+	}
+	if sessions, err := parseBgpSessions(json.RawMessage("")); err != nil || len(sessions) != 0 {
+		t.Errorf("expected no sessions for empty body, but got %v, %v", sessions, err)
+	}
+}
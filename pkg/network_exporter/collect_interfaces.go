@@ -17,23 +17,32 @@ package exporter
 import (
 	"crypto/sha1"
 	"fmt"
-	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // GetInterfaces collects interface related metrics.
-func (n *NetworkNode) GetInterfaces(cli *api.Client) {
+func (n *NetworkNode) GetInterfaces(cli DeviceClient) {
+	start := time.Now()
 	ifaces, err := cli.GetInterfaces()
+	n.observeAPIRequest("show interface", start, err)
 	if err != nil {
 		log.Debugf("%s: GetInterfaces() failed (host: %s, target: %s): %s", n.UUID, n.Name, n.target, err)
 		n.IncrementErrorCounter()
+		n.setLastError("interfaces", err)
 		return
 	}
+	n.setLastError("interfaces", nil)
+	collected := time.Now()
+	seen := make(map[string]bool)
 	// Interface metrics
 	for _, iface := range ifaces {
+		if !n.ifaceFilter.match(iface.Name, iface.Props.State) {
+			continue
+		}
 		var _uuid string
 		// Interface UUID
 		if v, exists := n.Interfaces[iface.Name]; !exists {
@@ -42,6 +51,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 			hash.Write([]byte(iface.Name))
 			_uuid = fmt.Sprintf("%x", hash.Sum(nil))
 			n.Interfaces[iface.Name] = _uuid
+			n.interfaceNames[_uuid] = iface.Name
 		} else {
 			_uuid = v
 		}
@@ -53,7 +63,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 		*/
 
 		// Metrics
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceName,
 			prometheus.GaugeValue,
 			1,
@@ -61,7 +71,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 			_uuid,
 			iface.Name,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceLocalIndex,
 			prometheus.GaugeValue,
 			float64(iface.LocalIndex),
@@ -74,7 +84,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 		} else {
 			_ifaceDescription = iface.Description
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceDescription,
 			prometheus.GaugeValue,
 			1,
@@ -82,36 +92,46 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 			_uuid,
 			_ifaceDescription,
 		))
+		if n.descrParser != nil {
+			if values, found := n.descrParser.parse(iface.Description); found {
+				n.metrics = append(n.metrics, n.newConstMetric(
+					n.descrParser.info.desc,
+					prometheus.GaugeValue,
+					1,
+					append([]string{n.UUID, _uuid}, values...)...,
+				))
+			}
+		}
 		// Various Metrics
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceMetricBandwidth,
 			prometheus.GaugeValue,
 			float64(iface.Metrics.Bandwidth),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceMetricDelay,
 			prometheus.GaugeValue,
 			float64(iface.Metrics.Delay),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceMetricReliability,
 			prometheus.GaugeValue,
 			float64(iface.Metrics.Reliability),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceMetricRxload,
 			prometheus.GaugeValue,
 			float64(iface.Metrics.Rxload),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceMetricTxload,
 			prometheus.GaugeValue,
 			float64(iface.Metrics.Txload),
@@ -119,308 +139,308 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 			_uuid,
 		))
 		// Various Counters
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterBabbles,
 			prometheus.CounterValue,
 			float64(iface.Counters.Babbles),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterBadEtherTypeDrops,
 			prometheus.CounterValue,
 			float64(iface.Counters.BadEtherTypeDrops),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterBadProtocolDrops,
 			prometheus.CounterValue,
 			float64(iface.Counters.BadProtocolDrops),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterNoCarrier,
 			prometheus.CounterValue,
 			float64(iface.Counters.NoCarrier),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterDribble,
 			prometheus.CounterValue,
 			float64(iface.Counters.Dribble),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputFrameErrors,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputFrameErrors),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputDiscards,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputDiscards),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputErrors,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputErrors),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputPause,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputPause),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputOverruns,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputOverruns),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputIfaceDownDrops,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputIfaceDownDrops),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputBytes,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputBytes),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputUnicastBytes,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputUnicastBytes),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputPackets,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputPackets),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputUnicastPackets,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputUnicastPackets),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputBroadcastPackets,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputBroadcastPackets),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputMulticastPackets,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputMulticastPackets),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputJumboPackets,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputJumboPackets),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputCompressed,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputCompressed),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterInputFifo,
 			prometheus.CounterValue,
 			float64(iface.Counters.InputFifo),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterLateCollisions,
 			prometheus.CounterValue,
 			float64(iface.Counters.LateCollisions),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterLostCarrier,
 			prometheus.CounterValue,
 			float64(iface.Counters.LostCarrier),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputDiscards,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputDiscards),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputErrors,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputErrors),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputPause,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputPause),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputUnderruns,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputUnderruns),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputBytes,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputBytes),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputUnicastBytes,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputUnicastBytes),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputPackets,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputPackets),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputUnicastPackets,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputUnicastPackets),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputBroadcastPackets,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputBroadcastPackets),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputMulticastPackets,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputMulticastPackets),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputJumboPackets,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputJumboPackets),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputCarrierErrors,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputCarrierErrors),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterCollisions,
 			prometheus.CounterValue,
 			float64(iface.Counters.Collisions),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterOutputFifo,
 			prometheus.CounterValue,
 			float64(iface.Counters.OutputFifo),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterWatchdog,
 			prometheus.CounterValue,
 			float64(iface.Counters.Watchdog),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterStormSuppression,
 			prometheus.CounterValue,
 			float64(iface.Counters.StormSuppression),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterIgnored,
 			prometheus.CounterValue,
 			float64(iface.Counters.Ignored),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterRunts,
 			prometheus.CounterValue,
 			float64(iface.Counters.Runts),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterCrcErrors,
 			prometheus.CounterValue,
 			float64(iface.Counters.CrcErrors),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterDeferred,
 			prometheus.CounterValue,
 			float64(iface.Counters.Deferred),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterNoBufferReceivedErrors,
 			prometheus.CounterValue,
 			float64(iface.Counters.NoBufferReceivedErrors),
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceCounterResets,
 			prometheus.CounterValue,
 			float64(iface.Counters.Resets),
@@ -432,7 +452,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 		if iface.Props.BeaconEnabled {
 			_ifacePropsBeaconEnabled = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifacePropBeaconEnabled,
 			prometheus.GaugeValue,
 			_ifacePropsBeaconEnabled,
@@ -443,7 +463,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 		if iface.Props.AutoNegotiationEnabled {
 			_ifacePropsAutoNegotiationEnabled = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifacePropAutoNegotiationEnabled,
 			prometheus.GaugeValue,
 			_ifacePropsAutoNegotiationEnabled,
@@ -454,14 +474,14 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 		if iface.Props.MdixEnabled {
 			_ifacePropsMdixEnabled = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifacePropMdixEnabled,
 			prometheus.GaugeValue,
 			_ifacePropsMdixEnabled,
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifacePropMTU,
 			prometheus.GaugeValue,
 			float64(iface.Props.MTU),
@@ -479,7 +499,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 		default:
 			_ifacePropsDuplex = 0
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifacePropDuplex,
 			prometheus.GaugeValue,
 			_ifacePropsDuplex,
@@ -514,14 +534,20 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 				_ifacePropsSpeed = speedVal * speedMulti
 			}
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifacePropSpeed,
 			prometheus.GaugeValue,
 			_ifacePropsSpeed,
 			n.UUID,
 			_uuid,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		seen[_uuid] = true
+		prev, cleared := n.updateIfaceSample(_uuid, collected, &iface.Counters)
+		n.addIfaceCountersCleared(_uuid)
+		if n.ifaceRates && prev != nil && !cleared {
+			n.addIfaceRates(_uuid, prev, n.ifaceSamples[_uuid], _ifacePropsSpeed)
+		}
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifacePropEncapsulatedVlan,
 			prometheus.GaugeValue,
 			float64(iface.Props.EncapsulatedVlan),
@@ -533,7 +559,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 		if iface.Props.State == "up" {
 			_ifacePropsState = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifacePropState,
 			prometheus.GaugeValue,
 			_ifacePropsState,
@@ -544,7 +570,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 		if iface.Props.AdminState == "up" {
 			_ifacePropsAdminState = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifacePropAdminState,
 			prometheus.GaugeValue,
 			_ifacePropsAdminState,
@@ -555,7 +581,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 		if iface.Props.ParentInterface != "" {
 			_ifaceIsSubinterface = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceIsSubinterface,
 			prometheus.GaugeValue,
 			_ifaceIsSubinterface,
@@ -566,7 +592,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 		if iface.Props.IPAddress != "" {
 			_ifaceIsRoutedMode = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceIsRoutedMode,
 			prometheus.GaugeValue,
 			_ifaceIsRoutedMode,
@@ -577,7 +603,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 		if iface.Props.Mode == "access" {
 			_ifaceIsAccessMode = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			ifaceIsAccessMode,
 			prometheus.GaugeValue,
 			_ifaceIsAccessMode,
@@ -585,7 +611,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 			_uuid,
 		))
 		if iface.Props.IPAddress != "" {
-			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			n.metrics = append(n.metrics, n.newConstMetric(
 				ifacePropIPAddress,
 				prometheus.GaugeValue,
 				1,
@@ -595,7 +621,7 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 			))
 		}
 		if iface.Props.HwAddr != "" {
-			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			n.metrics = append(n.metrics, n.newConstMetric(
 				ifacePropHWAddress,
 				prometheus.GaugeValue,
 				1,
@@ -605,4 +631,5 @@ func (n *NetworkNode) GetInterfaces(cli *api.Client) {
 			))
 		}
 	}
+	n.pruneIfaceSamples(seen)
 }
@@ -16,26 +16,30 @@ package exporter
 
 import (
 	"fmt"
-	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
+	"time"
 )
 
 // GetSystemEnvironment collects system environment related metrics,
 // e.g. fans, power supplies, sensors, etc.
-func (n *NetworkNode) GetSystemEnvironment(cli *api.Client) {
+func (n *NetworkNode) GetSystemEnvironment(cli DeviceClient) {
+	start := time.Now()
 	envt, err := cli.GetSystemEnvironment()
+	n.observeAPIRequest("show environment", start, err)
 	if err != nil {
 		log.Debugf("%s: GetSystemEnvironment() failed (host: %s, target: %s): %s", n.UUID, n.Name, n.target, err)
 		n.IncrementErrorCounter()
+		n.setLastError("environment", err)
 		return
 	}
+	n.setLastError("environment", nil)
 	for _, fan := range envt.Fans {
 		var fanStatus float64
 		if fan.Status == "Ok" || fan.Status == "OK" {
 			fanStatus = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			fanUp,
 			prometheus.GaugeValue,
 			fanStatus,
@@ -49,28 +53,28 @@ func (n *NetworkNode) GetSystemEnvironment(cli *api.Client) {
 		if ps.Status == "Ok" || ps.Status == "OK" {
 			psStatus = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
-			fanUp,
+		n.metrics = append(n.metrics, n.newConstMetric(
+			powerSupplyUp,
 			prometheus.GaugeValue,
 			psStatus,
 			n.UUID,
 			psName,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			powerSupplyPowerInput,
 			prometheus.GaugeValue,
 			ps.PowerInput,
 			n.UUID,
 			psName,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			powerSupplyPowerOutput,
 			prometheus.GaugeValue,
 			ps.PowerOutput,
 			n.UUID,
 			psName,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			powerSupplyPowerCapacity,
 			prometheus.GaugeValue,
 			ps.PowerCapacity,
@@ -84,28 +88,28 @@ func (n *NetworkNode) GetSystemEnvironment(cli *api.Client) {
 		if sensor.Status == "Ok" || sensor.Status == "OK" {
 			sensorStatus = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			sensorUp,
 			prometheus.GaugeValue,
 			sensorStatus,
 			n.UUID,
 			sensorName,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			sensorTemperature,
 			prometheus.GaugeValue,
 			sensor.Temperature,
 			n.UUID,
 			sensorName,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			sensorTemperatureThresholdHigh,
 			prometheus.GaugeValue,
 			sensor.ThresholdHigh,
 			n.UUID,
 			sensorName,
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			sensorTemperatureThresholdLow,
 			prometheus.GaugeValue,
 			sensor.ThresholdLow,
@@ -16,84 +16,88 @@ package exporter
 
 import (
 	"fmt"
-	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
+	"time"
 )
 
 // GetSystemResources collects system resource usage metrics.
 // That includes data about CPU, memory, and processes.
-func (n *NetworkNode) GetSystemResources(cli *api.Client) {
+func (n *NetworkNode) GetSystemResources(cli DeviceClient) {
+	start := time.Now()
 	rsc, err := cli.GetSystemResources()
+	n.observeAPIRequest("show system resources", start, err)
 	if err != nil {
 		log.Debugf("%s: GetSystemResources() failed (host: %s, target: %s): %s", n.UUID, n.Name, n.target, err)
 		n.IncrementErrorCounter()
+		n.setLastError("resources", err)
 		return
 	}
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.setLastError("resources", nil)
+	n.metrics = append(n.metrics, n.newConstMetric(
 		processUsageRunning,
 		prometheus.GaugeValue,
 		float64(rsc.Processes.Running),
 		n.UUID,
 	))
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		processUsageTotal,
 		prometheus.GaugeValue,
 		float64(rsc.Processes.Total),
 		n.UUID,
 	))
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		memoryUsageTotal,
 		prometheus.GaugeValue,
 		float64(rsc.Memory.Total),
 		n.UUID,
 	))
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		memoryUsageFree,
 		prometheus.GaugeValue,
 		float64(rsc.Memory.Free),
 		n.UUID,
 	))
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		memoryUsageUsed,
 		prometheus.GaugeValue,
 		float64(rsc.Memory.Used),
 		n.UUID,
 	))
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		cpuUsageTotalIdle,
 		prometheus.GaugeValue,
 		rsc.CPU.Idle,
 		n.UUID,
 	))
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		cpuUsageTotalKernel,
 		prometheus.GaugeValue,
 		rsc.CPU.Kernel,
 		n.UUID,
 	))
-	n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+	n.metrics = append(n.metrics, n.newConstMetric(
 		cpuUsageTotalUser,
 		prometheus.GaugeValue,
 		rsc.CPU.User,
 		n.UUID,
 	))
 	for _, c := range rsc.CPUs {
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			cpuUsagePerCPUIdle,
 			prometheus.GaugeValue,
 			c.Usage.Idle,
 			n.UUID,
 			fmt.Sprintf("%d", c.ID),
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			cpuUsagePerCPUKernel,
 			prometheus.GaugeValue,
 			c.Usage.Kernel,
 			n.UUID,
 			fmt.Sprintf("%d", c.ID),
 		))
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			cpuUsagePerCPUUser,
 			prometheus.GaugeValue,
 			c.Usage.User,
@@ -16,21 +16,31 @@ package exporter
 
 import (
 	"fmt"
-	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
+	"strings"
+	"time"
 )
 
 // GetTransceivers collects interface fiber transceiver related metrics.
-func (n *NetworkNode) GetTransceivers(cli *api.Client) {
+func (n *NetworkNode) GetTransceivers(cli DeviceClient) {
+	start := time.Now()
 	trs, err := cli.GetTransceivers()
+	n.observeAPIRequest("show interface transceiver details", start, err)
 	if err != nil {
 		log.Debugf("%s: GetTransceivers() failed (host: %s, target: %s): %s", n.UUID, n.Name, n.target, err)
 		n.IncrementErrorCounter()
+		n.setLastError("transceivers", err)
 		return
 	}
+	n.setLastError("transceivers", nil)
 	for _, t := range trs {
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		// the transceivers are collected in parallel with the interfaces,
+		// therefore the operational state of their interfaces is unknown
+		if !n.ifaceFilter.matchName(t.Interface) {
+			continue
+		}
+		n.metrics = append(n.metrics, n.newConstMetric(
 			transceiverUp,
 			prometheus.GaugeValue,
 			1,
@@ -39,9 +49,23 @@ func (n *NetworkNode) GetTransceivers(cli *api.Client) {
 			t.SerialNumber,
 			t.Name,
 		))
+		n.metrics = append(n.metrics, n.newConstMetric(
+			transceiverInfo,
+			prometheus.GaugeValue,
+			1,
+			n.UUID,
+			t.Interface,
+			t.PartNumber,
+			t.Revision,
+			getTransceiverFormFactor(t.Type, t.CiscoID),
+			getTransceiverMediaType(t.Type),
+			t.Wavelength,
+			t.CableLength,
+			getTransceiverCiscoSupported(t.Name, t.CiscoPartNumber),
+		))
 		for _, lane := range t.Lanes {
 			laneID := fmt.Sprintf("%d", lane.ID)
-			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			n.metrics = append(n.metrics, n.newConstMetric(
 				transceiverLaneTemperature,
 				prometheus.GaugeValue,
 				lane.Temperature,
@@ -49,7 +73,7 @@ func (n *NetworkNode) GetTransceivers(cli *api.Client) {
 				t.Interface,
 				laneID,
 			))
-			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			n.metrics = append(n.metrics, n.newConstMetric(
 				transceiverLaneVoltage,
 				prometheus.GaugeValue,
 				lane.Voltage,
@@ -57,7 +81,7 @@ func (n *NetworkNode) GetTransceivers(cli *api.Client) {
 				t.Interface,
 				laneID,
 			))
-			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			n.metrics = append(n.metrics, n.newConstMetric(
 				transceiverLaneCurrent,
 				prometheus.GaugeValue,
 				lane.Current,
@@ -65,7 +89,7 @@ func (n *NetworkNode) GetTransceivers(cli *api.Client) {
 				t.Interface,
 				laneID,
 			))
-			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			n.metrics = append(n.metrics, n.newConstMetric(
 				transceiverLaneTxPower,
 				prometheus.GaugeValue,
 				lane.TxPower,
@@ -73,7 +97,7 @@ func (n *NetworkNode) GetTransceivers(cli *api.Client) {
 				t.Interface,
 				laneID,
 			))
-			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			n.metrics = append(n.metrics, n.newConstMetric(
 				transceiverLaneRxPower,
 				prometheus.GaugeValue,
 				lane.RxPower,
@@ -81,7 +105,7 @@ func (n *NetworkNode) GetTransceivers(cli *api.Client) {
 				t.Interface,
 				laneID,
 			))
-			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			n.metrics = append(n.metrics, n.newConstMetric(
 				transceiverLaneErrors,
 				prometheus.CounterValue,
 				lane.Errors,
@@ -92,3 +116,77 @@ func (n *NetworkNode) GetTransceivers(cli *api.Client) {
 		}
 	}
 }
+
+// getTransceiverFormFactor returns the form factor of a transceiver, e.g.
+// SFP+ or QSFP28, based on its type, e.g. QSFP-100G-SR4, or, if the type
+// is not conclusive, its Cisco identifier, e.g. QSFP (0xd).
+func getTransceiverFormFactor(transceiverType, ciscoID string) string {
+	s := strings.ToUpper(transceiverType)
+	switch {
+	case strings.HasPrefix(s, "QSFP-DD"):
+		return "QSFP-DD"
+	case strings.HasPrefix(s, "QSFP28"), strings.HasPrefix(s, "QSFP-100G"):
+		return "QSFP28"
+	case strings.HasPrefix(s, "QSFP+"), strings.HasPrefix(s, "QSFP-40G"), strings.HasPrefix(s, "QSFP-H40G"):
+		return "QSFP+"
+	case strings.HasPrefix(s, "SFP28"), strings.HasPrefix(s, "SFP-25G"), strings.HasPrefix(s, "SFP-H25G"):
+		return "SFP28"
+	case strings.HasPrefix(s, "SFP+"), strings.HasPrefix(s, "SFP-10G"), strings.HasPrefix(s, "SFP-H10G"):
+		return "SFP+"
+	case strings.HasPrefix(s, "SFP"), strings.HasPrefix(s, "GLC-"):
+		return "SFP"
+	case strings.HasPrefix(s, "QSFP"):
+		return "QSFP"
+	}
+	if arr := strings.Fields(ciscoID); len(arr) > 0 {
+		return strings.ToUpper(arr[0])
+	}
+	return "unknown"
+}
+
+// getTransceiverMediaType returns the media type of a transceiver, e.g.
+// SR, LR, DAC, or AOC, based on its type, e.g. 10Gbase-SR or SFP-H10GB-CU3M.
+func getTransceiverMediaType(transceiverType string) string {
+	tokens := strings.FieldsFunc(strings.ToUpper(transceiverType), func(r rune) bool {
+		return r == '-' || r == ' ' || r == '_'
+	})
+	mediaTypes := []struct {
+		prefix string
+		name   string
+	}{
+		{"AOC", "AOC"},
+		{"CU", "DAC"},
+		{"DAC", "DAC"},
+		{"CR", "DAC"},
+		{"LRM", "LRM"},
+		{"SR", "SR"},
+		{"LR", "LR"},
+		{"ER", "ER"},
+		{"ZR", "ZR"},
+		{"SX", "SX"},
+		{"LX", "LX"},
+		{"BIDI", "BIDI"},
+	}
+	for _, mt := range mediaTypes {
+		for _, token := range tokens {
+			if strings.HasPrefix(token, mt.prefix) {
+				return mt.name
+			}
+		}
+	}
+	for _, token := range tokens {
+		if token == "T" || strings.HasSuffix(token, "BASET") {
+			return "BASE-T"
+		}
+	}
+	return "unknown"
+}
+
+// getTransceiverCiscoSupported returns "yes" when a transceiver is a
+// Cisco-branded or Cisco-coded one, otherwise it returns "no".
+func getTransceiverCiscoSupported(vendor, ciscoPartNumber string) string {
+	if ciscoPartNumber != "" || strings.HasPrefix(strings.ToUpper(vendor), "CISCO") {
+		return "yes"
+	}
+	return "no"
+}
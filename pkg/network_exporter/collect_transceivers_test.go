@@ -0,0 +1,77 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	simulator "github.com/greenpau/network_exporter/pkg/nxapi_sim"
+	"strings"
+	"testing"
+)
+
+func TestTransceiverInventory(t *testing.T) {
+	testCases := []struct {
+		transceiverType string
+		ciscoID         string
+		formFactor      string
+		mediaType       string
+	}{
+		{"QSFP-100G-SR4", "QSFP (0xd)", "QSFP28", "SR"},
+		{"QSFP-40G-LR4", "QSFP (0xd)", "QSFP+", "LR"},
+		{"SFP-H10GB-CU3M", "SFP (0x3)", "SFP+", "DAC"},
+		{"QSFP-H40G-AOC10M", "QSFP (0xd)", "QSFP+", "AOC"},
+		{"10Gbase-SR", "SFP (0x3)", "SFP", "SR"},
+		{"1000base-T", "", "unknown", "BASE-T"},
+		{"", "", "unknown", "unknown"},
+	}
+	for _, tc := range testCases {
+		if v := getTransceiverFormFactor(tc.transceiverType, tc.ciscoID); v != tc.formFactor {
+			t.Errorf("%q: expected form factor %q, but got %q", tc.transceiverType, tc.formFactor, v)
+		}
+		if v := getTransceiverMediaType(tc.transceiverType); v != tc.mediaType {
+			t.Errorf("%q: expected media type %q, but got %q", tc.transceiverType, tc.mediaType, v)
+		}
+	}
+	if v := getTransceiverCiscoSupported("CISCO-FINISAR", ""); v != "yes" {
+		t.Errorf("expected Cisco-branded transceiver to be supported, but got %q", v)
+	}
+	if v := getTransceiverCiscoSupported("FS", ""); v != "no" {
+		t.Errorf("expected third-party transceiver to be unsupported, but got %q", v)
+	}
+}
+
+func TestTransceiverInterfaceFilter(t *testing.T) {
+	ts, err := simulator.NewTestServer(simulator.Options{Hostname: "ny-sw01"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer ts.Close()
+	for _, exclude := range []string{"", "^Ethernet1/1$"} {
+		e := newSimulatedExporter(t, ts)
+		e.Nodes["ny-sw01"].ifaceFilter, err = newInterfaceFilter("", exclude, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		body := scrapeSimulatedExporter(t, e, "")
+		exported := false
+		for _, line := range strings.Split(body, "\n") {
+			if strings.HasPrefix(line, "net_interface_transceiver") && strings.Contains(line, `"Ethernet1/1"`) {
+				exported = true
+			}
+		}
+		if exported != (exclude == "") {
+			t.Errorf("exclude %q: expected the transceiver of Ethernet1/1 to be exported %t, but got %t", exclude, exclude == "", exported)
+		}
+	}
+}
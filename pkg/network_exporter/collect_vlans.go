@@ -17,20 +17,24 @@ package exporter
 import (
 	"crypto/sha1"
 	"fmt"
-	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
 	"strconv"
+	"time"
 )
 
 // GetVlans collects VLAN related metrics.
-func (n *NetworkNode) GetVlans(cli *api.Client) {
+func (n *NetworkNode) GetVlans(cli DeviceClient) {
+	start := time.Now()
 	vlans, err := cli.GetVlans()
+	n.observeAPIRequest("show vlan", start, err)
 	if err != nil {
 		log.Debugf("%s: GetVlans() failed (host: %s, target: %s): %s", n.UUID, n.Name, n.target, err)
 		n.IncrementErrorCounter()
+		n.setLastError("vlans", err)
 		return
 	}
+	n.setLastError("vlans", nil)
 	for _, vlan := range vlans {
 		var _uuid string
 		// VLAN UUID
@@ -40,12 +44,13 @@ func (n *NetworkNode) GetVlans(cli *api.Client) {
 			hash.Write([]byte(vlan.ID))
 			_uuid = fmt.Sprintf("%x", hash.Sum(nil))
 			n.Vlans[vlan.ID] = _uuid
+			n.vlanNames[_uuid] = vlan.ID
 		} else {
 			_uuid = v
 		}
 		// Metrics
 		if v, err := strconv.Atoi(vlan.ID); err == nil {
-			n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+			n.metrics = append(n.metrics, n.newConstMetric(
 				vlanID,
 				prometheus.GaugeValue,
 				float64(v),
@@ -53,7 +58,7 @@ func (n *NetworkNode) GetVlans(cli *api.Client) {
 				_uuid,
 			))
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			vlanName,
 			prometheus.GaugeValue,
 			1,
@@ -66,7 +71,7 @@ func (n *NetworkNode) GetVlans(cli *api.Client) {
 		if vlan.State == "active" {
 			_vlanState = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			vlanState,
 			prometheus.GaugeValue,
 			_vlanState,
@@ -78,7 +83,7 @@ func (n *NetworkNode) GetVlans(cli *api.Client) {
 		if vlan.ShutdownState == "noshutdown" {
 			_vlanShutdownState = 1
 		}
-		n.metrics = append(n.metrics, prometheus.MustNewConstMetric(
+		n.metrics = append(n.metrics, n.newConstMetric(
 			vlanShutdownState,
 			prometheus.GaugeValue,
 			_vlanShutdownState,
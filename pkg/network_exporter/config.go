@@ -0,0 +1,300 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// knownSubsystems are the subsystems the exporter collects data from.
+var knownSubsystems = []string{"interfaces", "vlans", "environment", "resources", "transceivers", "bgp"}
+
+// knownModules are the network operating systems the exporter collects
+// data from.
+var knownModules = []string{"cisco_nxos"}
+
+// Config is the configuration file of the exporter. The command line
+// arguments take precedence over the settings in the file.
+type Config struct {
+	Listen      ListenConfig             `yaml:"listen"`
+	Auth        AuthConfig               `yaml:"auth"`
+	Inventory   InventoryConfig          `yaml:"inventory"`
+	Credentials CredentialOptions        `yaml:"credentials"`
+	RemoteWrite RemoteWriteConfig        `yaml:"remote_write"`
+	Modules     map[string]*NodeSettings `yaml:"modules"`
+	Groups      map[string]*NodeSettings `yaml:"groups"`
+	Nodes       map[string]*NodeSettings `yaml:"nodes"`
+}
+
+// ListenConfig are the settings of the exporter's HTTP listener.
+type ListenConfig struct {
+	Address             string `yaml:"address"`
+	MetricsPath         string `yaml:"metrics_path"`
+	ExporterMetricsPath string `yaml:"exporter_metrics_path"`
+	TLSCert             string `yaml:"tls_cert"`
+	TLSKey              string `yaml:"tls_key"`
+	ClientCA            string `yaml:"client_ca"`
+	ClientCertRequired  bool   `yaml:"client_cert_required"`
+}
+
+// AuthConfig are the settings of the access to the exporter.
+type AuthConfig struct {
+	Tokens         []string `yaml:"tokens"`
+	TokenFile      string   `yaml:"token_file"`
+	ClientSubjects []string `yaml:"client_subjects"`
+}
+
+// InventoryConfig are the sources of network nodes and their credentials.
+type InventoryConfig struct {
+	File     string `yaml:"file"`
+	Vault    string `yaml:"vault"`
+	VaultKey string `yaml:"vault_key"`
+	// The interval, in seconds, between inventory refreshes. Zero
+	// disables the refreshes.
+	RefreshInterval int           `yaml:"refresh_interval"`
+	Netbox          NetboxOptions `yaml:"netbox"`
+}
+
+// RemoteWriteConfig are the settings of the push mode.
+type RemoteWriteConfig struct {
+	URL             string            `yaml:"url"`
+	Interval        int               `yaml:"interval"`
+	BatchSize       int               `yaml:"batch_size"`
+	BufferSize      int               `yaml:"buffer_size"`
+	Job             string            `yaml:"job"`
+	ExternalLabels  map[string]string `yaml:"external_labels"`
+	Username        string            `yaml:"username"`
+	PasswordFile    string            `yaml:"password_file"`
+	BearerTokenFile string            `yaml:"bearer_token_file"`
+}
+
+// NodeSettings are the collection settings of network nodes. The
+// settings of a module are the defaults for the nodes of the module.
+// The settings of groups and nodes override them.
+type NodeSettings struct {
+	Subsystems   []string `yaml:"subsystems"`
+	Timeout      int      `yaml:"timeout"`
+	PollInterval int64    `yaml:"poll_interval"`
+	TLSVerify    *bool    `yaml:"tls_verify"`
+	TLSCAFile    string   `yaml:"tls_ca_file"`
+}
+
+// LoadConfig reads and validates a configuration file.
+func LoadConfig(fp string) (*Config, error) {
+	data, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %s", err)
+	}
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %s", fp, err)
+	}
+	// the relative paths are relative to the directory of the file
+	dir := filepath.Dir(fp)
+	for _, p := range []*string{
+		&cfg.Listen.TLSCert,
+		&cfg.Listen.TLSKey,
+		&cfg.Listen.ClientCA,
+		&cfg.Auth.TokenFile,
+		&cfg.Inventory.File,
+		&cfg.Inventory.Vault,
+		&cfg.Inventory.VaultKey,
+		&cfg.Credentials.Dir,
+		&cfg.RemoteWrite.PasswordFile,
+		&cfg.RemoteWrite.BearerTokenFile,
+	} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	for _, m := range []map[string]*NodeSettings{cfg.Modules, cfg.Groups, cfg.Nodes} {
+		for _, s := range m {
+			if s != nil && s.TLSCAFile != "" && !filepath.IsAbs(s.TLSCAFile) {
+				s.TLSCAFile = filepath.Join(dir, s.TLSCAFile)
+			}
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %s", fp, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration for errors. It reports all the
+// errors found.
+func (cfg *Config) Validate() error {
+	errors := []string{}
+	if cfg.Listen.Address != "" {
+		if _, _, err := net.SplitHostPort(cfg.Listen.Address); err != nil {
+			errors = append(errors, fmt.Sprintf("listen.address: %s", err))
+		}
+	}
+	for k, v := range map[string]string{
+		"listen.metrics_path":          cfg.Listen.MetricsPath,
+		"listen.exporter_metrics_path": cfg.Listen.ExporterMetricsPath,
+	} {
+		if v != "" && !strings.HasPrefix(v, "/") {
+			errors = append(errors, fmt.Sprintf("%s: %q must start with /", k, v))
+		}
+	}
+	if (cfg.Listen.TLSCert == "") != (cfg.Listen.TLSKey == "") {
+		errors = append(errors, "listen: both tls_cert and tls_key are required")
+	}
+	if cfg.Listen.ClientCertRequired && cfg.Listen.ClientCA == "" {
+		errors = append(errors, "listen.client_cert_required: client_ca is required")
+	}
+	for k, v := range map[string]string{
+		"listen.tls_cert":                cfg.Listen.TLSCert,
+		"listen.tls_key":                 cfg.Listen.TLSKey,
+		"listen.client_ca":               cfg.Listen.ClientCA,
+		"auth.token_file":                cfg.Auth.TokenFile,
+		"inventory.file":                 cfg.Inventory.File,
+		"inventory.vault":                cfg.Inventory.Vault,
+		"inventory.vault_key":            cfg.Inventory.VaultKey,
+		"credentials.dir":                cfg.Credentials.Dir,
+		"remote_write.password_file":     cfg.RemoteWrite.PasswordFile,
+		"remote_write.bearer_token_file": cfg.RemoteWrite.BearerTokenFile,
+	} {
+		if v == "" {
+			continue
+		}
+		if _, err := os.Stat(v); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %s", k, err))
+		}
+	}
+	for _, token := range cfg.Auth.Tokens {
+		if token == "" {
+			errors = append(errors, "auth.tokens: empty token")
+		}
+	}
+	if cfg.Inventory.RefreshInterval < 0 {
+		errors = append(errors, "inventory.refresh_interval: must not be negative")
+	}
+	if cfg.Inventory.Netbox.URL != "" {
+		if _, err := newNetboxInventoryProvider(cfg.Inventory.Netbox); err != nil {
+			errors = append(errors, fmt.Sprintf("inventory.netbox: %s", err))
+		}
+	}
+	if cfg.RemoteWrite.URL != "" {
+		if _, err := newRemoteWriter(RemoteWriteOptions{URL: cfg.RemoteWrite.URL, ExternalLabels: cfg.RemoteWrite.ExternalLabels}); err != nil {
+			errors = append(errors, fmt.Sprintf("remote_write: %s", err))
+		}
+	}
+	for name := range cfg.Modules {
+		if !stringInSlice(name, knownModules) {
+			errors = append(errors, fmt.Sprintf("modules.%s: unsupported module", name))
+		}
+	}
+	for section, m := range map[string]map[string]*NodeSettings{
+		"modules": cfg.Modules,
+		"groups":  cfg.Groups,
+		"nodes":   cfg.Nodes,
+	} {
+		for name, s := range m {
+			if err := s.validate(); err != nil {
+				errors = append(errors, fmt.Sprintf("%s.%s: %s", section, name, err))
+			}
+		}
+	}
+	if len(errors) > 0 {
+		sort.Strings(errors)
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+func (s *NodeSettings) validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if s.PollInterval < 0 {
+		return fmt.Errorf("poll_interval must not be negative")
+	}
+	if s.TLSCAFile != "" {
+		if _, err := os.Stat(s.TLSCAFile); err != nil {
+			return fmt.Errorf("tls_ca_file: %s", err)
+		}
+	}
+	return validateSubsystems(s.Subsystems)
+}
+
+// validateSubsystems checks whether the subsystems are known to the exporter.
+func validateSubsystems(subsystems []string) error {
+	for _, v := range subsystems {
+		found := false
+		for _, k := range knownSubsystems {
+			if v == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unsupported subsystem %q, expected one of %s", v, strings.Join(knownSubsystems, ", "))
+		}
+	}
+	return nil
+}
+
+// merge overrides the settings with the non-empty settings of another
+// instance.
+func (s *NodeSettings) merge(o *NodeSettings) {
+	if o == nil {
+		return
+	}
+	if len(o.Subsystems) > 0 {
+		s.Subsystems = append([]string{}, o.Subsystems...)
+	}
+	if o.Timeout > 0 {
+		s.Timeout = o.Timeout
+	}
+	if o.PollInterval > 0 {
+		s.PollInterval = o.PollInterval
+	}
+	if o.TLSVerify != nil {
+		v := *o.TLSVerify
+		s.TLSVerify = &v
+	}
+	if o.TLSCAFile != "" {
+		s.TLSCAFile = o.TLSCAFile
+	}
+}
+
+// getNodeSettings returns the settings of a node. The module settings
+// are overridden by the settings of the groups, in alphabetical order,
+// and then by the settings of the node itself.
+func (cfg *Config) getNodeSettings(name, module string, groups []string) *NodeSettings {
+	s := &NodeSettings{}
+	if cfg == nil {
+		return s
+	}
+	s.merge(cfg.Modules[module])
+	sortedGroups := append([]string{}, groups...)
+	sort.Strings(sortedGroups)
+	for _, g := range sortedGroups {
+		s.merge(cfg.Groups[g])
+	}
+	s.merge(cfg.Nodes[name])
+	return s
+}
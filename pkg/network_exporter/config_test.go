@@ -0,0 +1,133 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig("../../assets/demo/default/config.yml")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s := cfg.getNodeSettings("ny-sw04", "cisco_nxos", []string{"ny5-cisco", "cisco"})
+	if s.PollInterval != 60 || s.Timeout != 5 {
+		t.Errorf("expected the group to override poll interval, but got %+v", s)
+	}
+	s = cfg.getNodeSettings("ny-sw01", "cisco_nxos", []string{"ny4-cisco"})
+	if s.PollInterval != 15 || s.Timeout != 10 {
+		t.Errorf("expected the node to override timeout, but got %+v", s)
+	}
+	expected := []string{"interfaces", "vlans", "environment", "resources", "transceivers"}
+	if !reflect.DeepEqual(s.Subsystems, expected) {
+		t.Errorf("expected subsystems %v, but got %v", expected, s.Subsystems)
+	}
+	if s.TLSVerify == nil || *s.TLSVerify {
+		t.Errorf("expected tls verification to be disabled")
+	}
+
+	n := &NetworkNode{}
+	n.applySettings(s)
+	if n.isSubsystemEnabled("bgp") || !n.isSubsystemEnabled("vlans") {
+		t.Errorf("expected only the configured subsystems to be enabled")
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network_exporter")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	testCases := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"unknown field", "listen:\n  adress: :9533\n", "field adress not found"},
+		{"bad address", "listen:\n  address: 9533\n", "listen.address"},
+		{"tls key", "listen:\n  tls_cert: /etc/ssl/cert.pem\n", "both tls_cert and tls_key are required"},
+		{"missing file", "inventory:\n  file: /nonexistent/hosts\n", "inventory.file"},
+		{"module", "modules:\n  juniper_junos:\n    timeout: 5\n", "modules.juniper_junos: unsupported module"},
+		{"subsystem", "nodes:\n  ny-sw01:\n    subsystems: [bfd]\n", `nodes.ny-sw01: unsupported subsystem "bfd"`},
+		{"timeout", "groups:\n  ny4:\n    timeout: -1\n", "groups.ny4: timeout must not be negative"},
+		{"tls ca file", "nodes:\n  ny-sw01:\n    tls_ca_file: /nonexistent/ca.pem\n", "nodes.ny-sw01: tls_ca_file"},
+	}
+	for _, tc := range testCases {
+		fp := filepath.Join(dir, "config.yml")
+		if err := ioutil.WriteFile(fp, []byte(tc.data), 0600); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		_, err := LoadConfig(fp)
+		if err == nil {
+			t.Errorf("%s: expected error, but got none", tc.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%s: expected error to contain %q, but got %q", tc.name, tc.expected, err)
+		}
+	}
+}
+
+func TestVariableSettings(t *testing.T) {
+	cfg, err := LoadConfig("../../assets/demo/default/config.yml")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	e := &Exporter{
+		timeout:      5,
+		pollInterval: 15,
+		Modules:      map[string]bool{"cisco_nxos": true},
+		config:       cfg,
+	}
+	n, err := e.newNetworkNode("ny-sw01", map[string]string{
+		"os":                     "cisco_nxos",
+		"exporter_timeout":       "30",
+		"exporter_poll_interval": "120",
+		"exporter_subsystems":    "vlans, interfaces",
+	}, []string{"wan"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n.timeout != 30 || n.pollInterval != 120 || n.getEnabledSubsystems() != "interfaces,vlans" {
+		t.Errorf("expected the variables to override the config file, but got timeout %d, poll interval %d, subsystems %s",
+			n.timeout, n.pollInterval, n.getEnabledSubsystems())
+	}
+	// the arguments passed explicitly take precedence over the file
+	e.settings = &NodeSettings{Timeout: 7, PollInterval: 45}
+	n, err = e.newNetworkNode("ny-sw01", map[string]string{"os": "cisco_nxos"}, []string{"ny4-cisco"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n.timeout != 7 || n.pollInterval != 45 {
+		t.Errorf("expected the arguments to override the config file, but got timeout %d, poll interval %d",
+			n.timeout, n.pollInterval)
+	}
+	for k, v := range map[string]string{
+		"exporter_timeout":       "slow",
+		"exporter_poll_interval": "0",
+		"exporter_subsystems":    "interfaces,bfd",
+	} {
+		if _, err := e.newNetworkNode("ny-sw01", map[string]string{"os": "cisco_nxos", k: v}, nil); err == nil {
+			t.Errorf("expected error for %s %q", k, v)
+		}
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	ansible "github.com/greenpau/go-ansible-db/pkg/db"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CredentialOptions are the sources of the credentials for network
+// nodes, in addition to Ansible Vault.
+type CredentialOptions struct {
+	// The prefix of the environment variables with credentials, e.g.
+	// NETWORK_EXPORTER_CRED_1_USERNAME, NETWORK_EXPORTER_CRED_1_PASSWORD.
+	EnvPrefix string `yaml:"env_prefix"`
+	// The directory with a subdirectory per credential. Each subdirectory
+	// has a file per field, e.g. Kubernetes secret mounts.
+	Dir string `yaml:"dir"`
+	// The command printing the credentials in JSON format.
+	Command string `yaml:"command"`
+	// The address of HashiCorp Vault server, the path to the KV secret
+	// with the credentials, e.g. secret/data/network-exporter, and the
+	// token for accessing the secret.
+	VaultAddr  string `yaml:"vault_addr"`
+	VaultPath  string `yaml:"vault_path"`
+	VaultToken string `yaml:"vault_token"`
+}
+
+// credentialEntry is a credential along with the rules for matching it
+// to network nodes. It has the same fields as the credentials in
+// Ansible Vault.
+type credentialEntry struct {
+	Regex          string `yaml:"regex" json:"regex"`
+	Default        bool   `yaml:"default" json:"default"`
+	Priority       int    `yaml:"priority" json:"priority"`
+	Description    string `yaml:"description" json:"description"`
+	Username       string `yaml:"username" json:"username"`
+	Password       string `yaml:"password" json:"password"`
+	EnablePassword string `yaml:"password_enable" json:"password_enable"`
+}
+
+type credentialFile struct {
+	Credentials []*credentialEntry `yaml:"credentials" json:"credentials"`
+}
+
+// credentialProvider is a source of credentials for network nodes.
+type credentialProvider interface {
+	getCredentials(host string) ([]*credentialEntry, error)
+}
+
+// matches checks whether a credential applies to a host, i.e. whether
+// its regular expression matches the name of the host, or it is a
+// default credential.
+func (c *credentialEntry) matches(host string) bool {
+	if c.Regex != "" {
+		if matched, err := regexp.MatchString(c.Regex, host); err == nil && matched {
+			return true
+		}
+	}
+	return c.Default
+}
+
+// matchCredentials returns the credentials applying to a host.
+func matchCredentials(entries []*credentialEntry, host string) []*credentialEntry {
+	matched := []*credentialEntry{}
+	for _, c := range entries {
+		if c.matches(host) {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+// sortCredentials orders the credentials of a host the way Ansible Vault
+// does: the credentials with a matching regular expression come before
+// the default ones, and, within each group, the credentials with higher
+// priority come first.
+func sortCredentials(entries []*credentialEntry, host string) {
+	isSpecific := func(c *credentialEntry) bool {
+		if c.Regex == "" {
+			return false
+		}
+		matched, err := regexp.MatchString(c.Regex, host)
+		return err == nil && matched
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		si, sj := isSpecific(entries[i]), isSpecific(entries[j])
+		if si != sj {
+			return si
+		}
+		return entries[i].Priority > entries[j].Priority
+	})
+}
+
+// validateCredentials checks the regular expressions of the credentials.
+func validateCredentials(source string, entries []*credentialEntry) error {
+	for i, c := range entries {
+		if c.Username == "" {
+			return fmt.Errorf("%s: credential %d has no username", source, i+1)
+		}
+		if c.Regex == "" && !c.Default {
+			return fmt.Errorf("%s: credential %d has neither regex nor default", source, i+1)
+		}
+		if c.Regex != "" {
+			if _, err := regexp.Compile(c.Regex); err != nil {
+				return fmt.Errorf("%s: credential %d has invalid regex: %s", source, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ansibleVaultProvider gets credentials from Ansible Vault.
+type ansibleVaultProvider struct {
+	vault *ansible.Vault
+}
+
+func (p *ansibleVaultProvider) getCredentials(host string) ([]*credentialEntry, error) {
+	creds, err := p.vault.GetCredentials(host)
+	if err != nil {
+		return nil, err
+	}
+	entries := []*credentialEntry{}
+	for _, c := range creds {
+		entries = append(entries, &credentialEntry{
+			Regex:          c.Regex,
+			Default:        c.Default,
+			Priority:       c.Priority,
+			Description:    c.Description,
+			Username:       c.Username,
+			Password:       c.Password,
+			EnablePassword: c.EnablePassword,
+		})
+	}
+	return entries, nil
+}
+
+// staticCredentialProvider holds the credentials loaded from environment
+// variables, files, commands, or HashiCorp Vault.
+type staticCredentialProvider struct {
+	entries []*credentialEntry
+}
+
+func (p *staticCredentialProvider) getCredentials(host string) ([]*credentialEntry, error) {
+	return matchCredentials(p.entries, host), nil
+}
+
+// setCredentialField sets a field of a credential by its name, e.g.
+// USERNAME or username.
+func setCredentialField(c *credentialEntry, k, v string) error {
+	switch strings.ToLower(k) {
+	case "regex":
+		c.Regex = v
+	case "default":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid default value %q", v)
+		}
+		c.Default = b
+	case "priority":
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid priority value %q", v)
+		}
+		c.Priority = i
+	case "description":
+		c.Description = v
+	case "username":
+		c.Username = v
+	case "password":
+		c.Password = v
+	case "password_enable":
+		c.EnablePassword = v
+	}
+	return nil
+}
+
+// newEnvCredentialProvider loads the credentials from the environment
+// variables named <prefix>_<id>_<field>, e.g. NETWORK_EXPORTER_CRED_NY_USERNAME.
+func newEnvCredentialProvider(prefix string, environ []string) (*staticCredentialProvider, error) {
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+	byID := make(map[string]*credentialEntry)
+	ids := []string{}
+	for _, kv := range environ {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], prefix) {
+			continue
+		}
+		name := strings.TrimPrefix(parts[0], prefix)
+		var id, field string
+		for _, f := range []string{"_PASSWORD_ENABLE", "_USERNAME", "_PASSWORD", "_REGEX", "_DEFAULT", "_PRIORITY", "_DESCRIPTION"} {
+			if strings.HasSuffix(name, f) {
+				id = strings.TrimSuffix(name, f)
+				field = strings.TrimPrefix(f, "_")
+				break
+			}
+		}
+		if id == "" {
+			continue
+		}
+		c, exists := byID[id]
+		if !exists {
+			c = &credentialEntry{}
+			byID[id] = c
+			ids = append(ids, id)
+		}
+		if err := setCredentialField(c, field, parts[1]); err != nil {
+			return nil, fmt.Errorf("environment variable %s: %s", parts[0], err)
+		}
+	}
+	sort.Strings(ids)
+	p := &staticCredentialProvider{}
+	for _, id := range ids {
+		if byID[id].Description == "" {
+			byID[id].Description = "environment credential " + id
+		}
+		p.entries = append(p.entries, byID[id])
+	}
+	if err := validateCredentials("environment", p.entries); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// newDirCredentialProvider loads the credentials from a directory. Each
+// subdirectory is a credential with a file per field, e.g. username,
+// password, regex, priority. When the directory itself has username
+// file, it is the only credential.
+func newDirCredentialProvider(dir string) (*staticCredentialProvider, error) {
+	dirs := []string{}
+	if _, err := os.Stat(filepath.Join(dir, "username")); err == nil {
+		dirs = append(dirs, dir)
+	} else {
+		items, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("error reading credentials directory: %s", err)
+		}
+		for _, item := range items {
+			if strings.HasPrefix(item.Name(), ".") {
+				continue
+			}
+			fp := filepath.Join(dir, item.Name())
+			if fi, err := os.Stat(fp); err == nil && fi.IsDir() {
+				dirs = append(dirs, fp)
+			}
+		}
+	}
+	p := &staticCredentialProvider{}
+	for _, d := range dirs {
+		c := &credentialEntry{Description: filepath.Base(d)}
+		for _, field := range []string{"regex", "default", "priority", "description", "username", "password", "password_enable"} {
+			data, err := ioutil.ReadFile(filepath.Join(d, field))
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, fmt.Errorf("error reading credential %s: %s", d, err)
+			}
+			if err := setCredentialField(c, field, strings.TrimRight(string(data), "\r\n")); err != nil {
+				return nil, fmt.Errorf("credential %s: %s", d, err)
+			}
+		}
+		p.entries = append(p.entries, c)
+	}
+	if err := validateCredentials(dir, p.entries); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// newCommandCredentialProvider runs a command and loads the credentials
+// from its output, e.g. {"credentials": [{"regex": "ny-sw0[1-9]",
+// "username": "admin", "password": "cisco", "priority": 10}]}.
+func newCommandCredentialProvider(command string, timeout time.Duration) (*staticCredentialProvider, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty credentials command")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running credentials command: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	f := &credentialFile{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("error parsing credentials command output: %s", err)
+	}
+	if err := validateCredentials("credentials command", f.Credentials); err != nil {
+		return nil, err
+	}
+	return &staticCredentialProvider{entries: f.Credentials}, nil
+}
+
+// newHashicorpVaultProvider loads the credentials from a KV secret in
+// HashiCorp Vault. The secret has credentials key with the list of the
+// credentials, either as a list or as a JSON or YAML string. Both KV
+// version 1 and 2 secrets are supported.
+func newHashicorpVaultProvider(addr, path, token string, timeout time.Duration) (*staticCredentialProvider, error) {
+	if addr == "" || path == "" {
+		return nil, fmt.Errorf("both vault address and secret path are required")
+	}
+	url := strings.TrimSuffix(addr, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating vault request: %s", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error reading vault secret: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading vault secret: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error reading vault secret %s: %s", path, resp.Status)
+	}
+	secret := &struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, secret); err != nil {
+		return nil, fmt.Errorf("error parsing vault secret: %s", err)
+	}
+	data := secret.Data
+	// KV version 2 nests the secret under data key along with metadata
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = inner
+		}
+	}
+	var raw []byte
+	switch v := data["credentials"].(type) {
+	case string:
+		raw = []byte(v)
+	case nil:
+		return nil, fmt.Errorf("vault secret %s has no credentials key", path)
+	default:
+		raw, err = json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing vault secret: %s", err)
+		}
+	}
+	entries := []*credentialEntry{}
+	if err := yaml.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing vault secret credentials: %s", err)
+	}
+	if err := validateCredentials("vault secret "+path, entries); err != nil {
+		return nil, err
+	}
+	return &staticCredentialProvider{entries: entries}, nil
+}
+
+// newCredentialProviders returns the credential providers configured by
+// the options.
+func newCredentialProviders(opts CredentialOptions) ([]credentialProvider, error) {
+	providers := []credentialProvider{}
+	if opts.EnvPrefix != "" {
+		p, err := newEnvCredentialProvider(opts.EnvPrefix, os.Environ())
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+	if opts.Dir != "" {
+		p, err := newDirCredentialProvider(opts.Dir)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+	if opts.Command != "" {
+		p, err := newCommandCredentialProvider(opts.Command, 30*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+	if opts.VaultAddr != "" || opts.VaultPath != "" {
+		token := opts.VaultToken
+		if token == "" {
+			token = os.Getenv("VAULT_TOKEN")
+		}
+		p, err := newHashicorpVaultProvider(opts.VaultAddr, opts.VaultPath, token, 30*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func getCredentialUsers(entries []*credentialEntry) []string {
+	users := []string{}
+	for _, c := range entries {
+		users = append(users, c.Username)
+	}
+	return users
+}
+
+func TestSortCredentials(t *testing.T) {
+	entries := []*credentialEntry{
+		{Username: "default-low", Default: true, Priority: 1},
+		{Username: "ny-low", Regex: "^ny-sw", Priority: 10},
+		{Username: "default-high", Default: true, Priority: 100},
+		{Username: "ny-high", Regex: "^ny-sw", Priority: 50},
+		{Username: "la", Regex: "^la-sw", Priority: 1000},
+	}
+	matched := matchCredentials(entries, "ny-sw01")
+	sortCredentials(matched, "ny-sw01")
+	expected := "[ny-high ny-low default-high default-low]"
+	if got := fmt.Sprintf("%v", getCredentialUsers(matched)); got != expected {
+		t.Errorf("expected credentials %s, but got %s", expected, got)
+	}
+}
+
+func TestEnvCredentialProvider(t *testing.T) {
+	environ := []string{
+		"NETWORK_EXPORTER_CRED_NY_USERNAME=admin",
+		"NETWORK_EXPORTER_CRED_NY_PASSWORD=cisco",
+		"NETWORK_EXPORTER_CRED_NY_REGEX=^ny-sw",
+		"NETWORK_EXPORTER_CRED_NY_PRIORITY=10",
+		"NETWORK_EXPORTER_CRED_ANY_USERNAME=readonly",
+		"NETWORK_EXPORTER_CRED_ANY_PASSWORD_ENABLE=enable",
+		"NETWORK_EXPORTER_CRED_ANY_DEFAULT=true",
+		"HOME=/root",
+	}
+	p, err := newEnvCredentialProvider("NETWORK_EXPORTER_CRED", environ)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	creds, _ := p.getCredentials("ny-sw01")
+	if len(creds) != 2 {
+		t.Fatalf("expected 2 credentials, but got %d", len(creds))
+	}
+	if creds[0].Username != "readonly" || creds[0].EnablePassword != "enable" || creds[0].Password != "" {
+		t.Errorf("unexpected default credential: %+v", creds[0])
+	}
+	if creds[1].Password != "cisco" || creds[1].Priority != 10 {
+		t.Errorf("unexpected ny credential: %+v", creds[1])
+	}
+	if creds, _ := p.getCredentials("la-sw01"); len(creds) != 1 {
+		t.Errorf("expected only the default credential for la-sw01, but got %d", len(creds))
+	}
+	environ = append(environ, "NETWORK_EXPORTER_CRED_NY_PRIORITY=high")
+	if _, err := newEnvCredentialProvider("NETWORK_EXPORTER_CRED", environ); err == nil {
+		t.Errorf("expected error for invalid priority")
+	}
+}
+
+func TestDirCredentialProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network_exporter")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"ny/username":      "admin\n",
+		"ny/password":      "cisco\n",
+		"ny/regex":         "^ny-sw",
+		"ny/priority":      "10",
+		"default/default":  "true",
+		"default/username": "readonly",
+		"default/password": "readonly",
+		// Kubernetes secret mounts have hidden directories
+		"..data/username": "ignored",
+	}
+	for k, v := range files {
+		fp := filepath.Join(dir, k)
+		if err := os.MkdirAll(filepath.Dir(fp), 0700); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := ioutil.WriteFile(fp, []byte(v), 0600); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	p, err := newDirCredentialProvider(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	creds, _ := p.getCredentials("ny-sw01")
+	sortCredentials(creds, "ny-sw01")
+	if len(creds) != 2 || creds[0].Password != "cisco" || creds[0].Description != "ny" {
+		t.Errorf("unexpected credentials: %v", getCredentialUsers(creds))
+	}
+	// a single secret mounted as the directory itself
+	p, err = newDirCredentialProvider(filepath.Join(dir, "ny"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if creds, _ := p.getCredentials("ny-sw01"); len(creds) != 1 {
+		t.Errorf("expected 1 credential, but got %d", len(creds))
+	}
+}
+
+func TestCommandCredentialProvider(t *testing.T) {
+	cmd := `echo {"credentials":[{"regex":"^ny-sw","username":"admin","password":"cisco"}]}`
+	p, err := newCommandCredentialProvider(cmd, 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if creds, _ := p.getCredentials("ny-sw01"); len(creds) != 1 || creds[0].Password != "cisco" {
+		t.Errorf("unexpected credentials: %v", getCredentialUsers(creds))
+	}
+	if _, err := newCommandCredentialProvider("false", 5*time.Second); err == nil {
+		t.Errorf("expected error for failed command")
+	}
+}
+
+func TestHashicorpVaultProvider(t *testing.T) {
+	// the stand-in for Vault dev server with KV version 1 and 2 engines
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			writeJSON(w, http.StatusForbidden, map[string]interface{}{"errors": []string{"permission denied"}})
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/network-exporter":
+			w.Write([]byte(`{"data":{"data":{"credentials":[{"regex":"^ny-sw","username":"admin","password":"cisco","priority":10}]},"metadata":{"version":1}}}`))
+		case "/v1/kv/network-exporter":
+			w.Write([]byte(`{"data":{"credentials":"- default: true\n  username: readonly\n  password: readonly\n"}}`))
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+		}
+	}))
+	defer ts.Close()
+
+	p, err := newHashicorpVaultProvider(ts.URL, "secret/data/network-exporter", "root", 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if creds, _ := p.getCredentials("ny-sw01"); len(creds) != 1 || creds[0].Priority != 10 {
+		t.Errorf("unexpected credentials: %v", getCredentialUsers(creds))
+	}
+	p, err = newHashicorpVaultProvider(ts.URL, "kv/network-exporter", "root", 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if creds, _ := p.getCredentials("la-sw01"); len(creds) != 1 || creds[0].Username != "readonly" {
+		t.Errorf("unexpected credentials: %v", getCredentialUsers(creds))
+	}
+	if _, err := newHashicorpVaultProvider(ts.URL, "kv/network-exporter", "invalid", 5*time.Second); err == nil {
+		t.Errorf("expected error for invalid token")
+	}
+	if _, err := newHashicorpVaultProvider(ts.URL, "kv/missing", "root", 5*time.Second); err == nil {
+		t.Errorf("expected error for missing secret")
+	}
+}
+
+func TestInventoryCredentialSource(t *testing.T) {
+	e := &Exporter{}
+	hosts := []*inventoryHost{
+		{Name: "ny-sw01", Variables: map[string]string{"os": "cisco_nxos"}},
+	}
+	if _, err := e.getCredentialProviders(hosts); err == nil {
+		t.Errorf("expected error for no credential sources, but got none")
+	}
+	hosts = append(hosts, &inventoryHost{
+		Name:      "ny-sw02",
+		Variables: map[string]string{"os": "cisco_nxos", "ansible_user": "netops", "ansible_password": "secret"},
+	})
+	providers, err := e.getCredentialProviders(hosts)
+	if err != nil {
+		t.Fatalf("expected the inventory credentials to be a credential source, but got %s", err)
+	}
+	if len(providers) != 0 {
+		t.Errorf("expected no credential providers, but got %d", len(providers))
+	}
+}
@@ -0,0 +1,468 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"strings"
+)
+
+// The template variable queries. The node and iface variables have the
+// label values as values and the names of the nodes and interfaces as
+// text, whatever the label strategy.
+const (
+	grafanaNodeRegex  = `/name="(?<text>[^"]+)".*node="(?<value>[^"]+)"/`
+	grafanaIfaceRegex = `/iface="(?<value>[^"]+)".*name="(?<text>[^"]+)"/`
+)
+
+type grafanaDashboard struct {
+	UID           string            `json:"uid"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	Tags          []string          `json:"tags"`
+	Editable      bool              `json:"editable"`
+	SchemaVersion int               `json:"schemaVersion"`
+	Version       int               `json:"version"`
+	Refresh       string            `json:"refresh"`
+	Time          grafanaTimeRange  `json:"time"`
+	Templating    grafanaTemplating `json:"templating"`
+	Links         []*grafanaLink    `json:"links"`
+	Panels        []*grafanaPanel   `json:"panels"`
+	nextID        int
+	gridPos       grafanaGridPos
+}
+
+type grafanaTimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type grafanaTemplating struct {
+	List []*grafanaVariable `json:"list"`
+}
+
+type grafanaDatasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type grafanaVariable struct {
+	Name       string             `json:"name"`
+	Label      string             `json:"label"`
+	Type       string             `json:"type"`
+	Query      string             `json:"query"`
+	Datasource *grafanaDatasource `json:"datasource,omitempty"`
+	Regex      string             `json:"regex,omitempty"`
+	Refresh    int                `json:"refresh,omitempty"`
+	Sort       int                `json:"sort,omitempty"`
+	Multi      bool               `json:"multi"`
+	IncludeAll bool               `json:"includeAll"`
+}
+
+type grafanaLink struct {
+	Title       string   `json:"title"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+	AsDropdown  bool     `json:"asDropdown"`
+	IncludeVars bool     `json:"includeVars"`
+	KeepTime    bool     `json:"keepTime"`
+}
+
+type grafanaGridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type grafanaPanel struct {
+	ID          int                 `json:"id"`
+	Type        string              `json:"type"`
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	GridPos     grafanaGridPos      `json:"gridPos"`
+	Datasource  *grafanaDatasource  `json:"datasource,omitempty"`
+	Targets     []*grafanaTarget    `json:"targets,omitempty"`
+	FieldConfig *grafanaFieldConfig `json:"fieldConfig,omitempty"`
+}
+
+type grafanaFieldConfig struct {
+	Defaults  grafanaFieldDefaults `json:"defaults"`
+	Overrides []interface{}        `json:"overrides"`
+}
+
+type grafanaFieldDefaults struct {
+	Unit string `json:"unit,omitempty"`
+}
+
+type grafanaTarget struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+	Instant      bool   `json:"instant,omitempty"`
+	help         string
+}
+
+var grafanaPrometheus = &grafanaDatasource{Type: "prometheus", UID: "${datasource}"}
+
+// newDashboard returns a dashboard with the datasource and job template
+// variables, and the links to the other dashboards of the exporter.
+func newDashboard(uid, title string) *grafanaDashboard {
+	d := &grafanaDashboard{
+		UID:           uid,
+		Title:         title,
+		Description:   fmt.Sprintf("Generated by %s %s", GetExporterName(), GetVersion()),
+		Tags:          []string{appName},
+		Editable:      true,
+		SchemaVersion: 36,
+		Version:       1,
+		Refresh:       "1m",
+		Time:          grafanaTimeRange{From: "now-6h", To: "now"},
+		Links: []*grafanaLink{
+			{
+				Title:       "Network Exporter",
+				Type:        "dashboards",
+				Tags:        []string{appName},
+				AsDropdown:  true,
+				IncludeVars: true,
+				KeepTime:    true,
+			},
+		},
+		Panels: []*grafanaPanel{},
+	}
+	d.Templating.List = []*grafanaVariable{
+		{
+			Name:  "datasource",
+			Label: "Data Source",
+			Type:  "datasource",
+			Query: "prometheus",
+		},
+		{
+			Name:       "job",
+			Label:      "Job",
+			Type:       "query",
+			Query:      fmt.Sprintf("label_values(%s, job)", metricName(nodeUp)),
+			Datasource: grafanaPrometheus,
+			Refresh:    2,
+			Sort:       1,
+			Multi:      true,
+			IncludeAll: true,
+		},
+	}
+	return d
+}
+
+// addNodeVariable adds the node template variable to a dashboard.
+func (d *grafanaDashboard) addNodeVariable(multi bool) {
+	d.Templating.List = append(d.Templating.List, &grafanaVariable{
+		Name:       "node",
+		Label:      "Node",
+		Type:       "query",
+		Query:      fmt.Sprintf("query_result(%s{job=~\"$job\"})", metricName(nodeHostname)),
+		Datasource: grafanaPrometheus,
+		Regex:      grafanaNodeRegex,
+		Refresh:    2,
+		Sort:       1,
+		Multi:      multi,
+		IncludeAll: multi,
+	})
+}
+
+// addIfaceVariable adds the iface template variable to a dashboard.
+func (d *grafanaDashboard) addIfaceVariable() {
+	d.Templating.List = append(d.Templating.List, &grafanaVariable{
+		Name:       "iface",
+		Label:      "Interface",
+		Type:       "query",
+		Query:      fmt.Sprintf("query_result(%s{job=~\"$job\", node=\"$node\"})", metricName(ifaceName)),
+		Datasource: grafanaPrometheus,
+		Regex:      grafanaIfaceRegex,
+		Refresh:    2,
+		Sort:       1,
+	})
+}
+
+// addRow adds a row to a dashboard. The panels following the row are
+// laid out below it.
+func (d *grafanaDashboard) addRow(title string) {
+	if d.gridPos.X > 0 {
+		d.gridPos.X = 0
+		d.gridPos.Y += d.gridPos.H
+	}
+	d.nextID++
+	d.Panels = append(d.Panels, &grafanaPanel{
+		ID:      d.nextID,
+		Type:    "row",
+		Title:   title,
+		GridPos: grafanaGridPos{X: 0, Y: d.gridPos.Y, W: 24, H: 1},
+	})
+	d.gridPos.Y++
+	d.gridPos.H = 0
+}
+
+// addPanel adds a panel with the given width and height to a dashboard.
+// The panel description is the help of the metrics of its targets.
+func (d *grafanaDashboard) addPanel(kind, title, unit string, w, h int, targets ...*grafanaTarget) {
+	if d.gridPos.X+w > 24 {
+		d.gridPos.X = 0
+		d.gridPos.Y += d.gridPos.H
+		d.gridPos.H = 0
+	}
+	d.nextID++
+	p := &grafanaPanel{
+		ID:          d.nextID,
+		Type:        kind,
+		Title:       title,
+		GridPos:     grafanaGridPos{X: d.gridPos.X, Y: d.gridPos.Y, W: w, H: h},
+		Datasource:  grafanaPrometheus,
+		Targets:     targets,
+		FieldConfig: &grafanaFieldConfig{Defaults: grafanaFieldDefaults{Unit: unit}, Overrides: []interface{}{}},
+	}
+	helps := []string{}
+	for i, t := range targets {
+		t.RefID = string(rune('A' + i))
+		if t.help != "" && !stringInSlice(t.help, helps) {
+			helps = append(helps, t.help)
+		}
+	}
+	p.Description = strings.Join(helps, " ")
+	d.Panels = append(d.Panels, p)
+	d.gridPos.X += w
+	if h > d.gridPos.H {
+		d.gridPos.H = h
+	}
+}
+
+// addGraph adds a half-width time series panel to a dashboard.
+func (d *grafanaDashboard) addGraph(title, unit string, targets ...*grafanaTarget) {
+	d.addPanel("timeseries", title, unit, 12, 8, targets...)
+}
+
+// addStat adds a quarter-width stat panel to a dashboard.
+func (d *grafanaDashboard) addStat(title, unit string, targets ...*grafanaTarget) {
+	d.addPanel("stat", title, unit, 6, 4, targets...)
+}
+
+// newTarget returns a query of a panel. The %s verbs of the expression
+// are replaced with the name of the metric of the descriptor.
+func newTarget(desc *prometheus.Desc, expr, legend string) *grafanaTarget {
+	info := descInfos[desc]
+	return &grafanaTarget{
+		Expr:         strings.Replace(expr, "%s", info.fqName, -1),
+		LegendFormat: legend,
+		help:         info.help,
+	}
+}
+
+// withNodeName adds the node_name label with the name of the node to the
+// series of an expression.
+func withNodeName(expr string) string {
+	return fmt.Sprintf("(%s) * on (node) group_left(node_name) label_replace(%s, \"node_name\", \"$1\", \"name\", \"(.*)\")",
+		expr, metricName(nodeHostname))
+}
+
+// withIfaceName adds the name label with the name of the interface to the
+// series of an expression.
+func withIfaceName(expr string) string {
+	return fmt.Sprintf("(%s) * on (node, iface) group_left(name) %s", expr, metricName(ifaceName))
+}
+
+func stringInSlice(s string, l []string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// getFleetDashboard returns the overview of all the nodes.
+func getFleetDashboard() *grafanaDashboard {
+	d := newDashboard("network-exporter-fleet", "Network Exporter / Fleet Overview")
+	d.addNodeVariable(true)
+	sel := `{job=~"$job", node=~"$node"}`
+
+	d.addRow("Nodes")
+	d.addStat("Nodes Up", "none", newTarget(nodeUp, "sum(%s"+sel+")", ""))
+	d.addStat("Nodes Down", "none", newTarget(nodeUp, "count(%s"+sel+" == 0) or vector(0)", ""))
+	d.addStat("Interfaces Up", "none", newTarget(ifacePropState, "sum(%s"+sel+")", ""))
+	d.addStat("Failed Requests", "none", newTarget(nodeErrors, "sum(increase(%s"+sel+"[$__range]))", ""))
+	d.addGraph("Node Status", "none",
+		newTarget(nodeUp, withNodeName("%s"+sel), "{{node_name}}"),
+	)
+	d.addGraph("Scrape Time", "s",
+		newTarget(nodeScrapeTime, withNodeName("%s"+sel), "{{node_name}}"),
+	)
+
+	d.addRow("Resources")
+	d.addGraph("CPU Utilization", "percent",
+		newTarget(cpuUsageTotalIdle, withNodeName("100 - %s"+sel), "{{node_name}}"),
+	)
+	d.addGraph("Memory Utilization", "percent",
+		&grafanaTarget{
+			Expr: withNodeName(fmt.Sprintf("100 * %s%s / (%s%s > 0)",
+				metricName(memoryUsageUsed), sel, metricName(memoryUsageTotal), sel)),
+			LegendFormat: "{{node_name}}",
+			help:         descInfos[memoryUsageUsed].help,
+		},
+	)
+
+	d.addRow("Interfaces")
+	d.addGraph("Top Interfaces by Errors", "pps",
+		&grafanaTarget{
+			Expr: withNodeName(withIfaceName(fmt.Sprintf("topk(10, rate(%s%s[$__rate_interval]) + rate(%s%s[$__rate_interval]))",
+				metricName(ifaceCounterInputErrors), sel, metricName(ifaceCounterOutputErrors), sel))),
+			LegendFormat: "{{node_name}} {{name}}",
+			help:         descInfos[ifaceCounterInputErrors].help,
+		},
+	)
+	d.addGraph("Top Interfaces by Discards", "pps",
+		&grafanaTarget{
+			Expr: withNodeName(withIfaceName(fmt.Sprintf("topk(10, rate(%s%s[$__rate_interval]) + rate(%s%s[$__rate_interval]))",
+				metricName(ifaceCounterInputDiscards), sel, metricName(ifaceCounterOutputDiscards), sel))),
+			LegendFormat: "{{node_name}} {{name}}",
+			help:         descInfos[ifaceCounterInputDiscards].help,
+		},
+	)
+
+	d.addRow("Environment")
+	d.addStat("Power Supplies Down", "none", newTarget(powerSupplyUp, "count(%s"+sel+" == 0) or vector(0)", ""))
+	d.addStat("Fans Down", "none", newTarget(fanUp, "count(%s"+sel+" == 0) or vector(0)", ""))
+	d.addStat("Sensors Down", "none", newTarget(sensorUp, "count(%s"+sel+" == 0) or vector(0)", ""))
+	d.addStat("Max Temperature", "celsius", newTarget(sensorTemperature, "max(%s"+sel+")", ""))
+	return d
+}
+
+// getNodeDashboard returns the drilldown into a node.
+func getNodeDashboard() *grafanaDashboard {
+	d := newDashboard("network-exporter-node", "Network Exporter / Node")
+	d.addNodeVariable(false)
+	sel := `{job=~"$job", node="$node"}`
+	rate := "rate(%s" + sel + "[$__rate_interval])"
+
+	d.addRow("Overview")
+	d.addStat("Up", "none", newTarget(nodeUp, "%s"+sel, ""))
+	d.addStat("Scrape Time", "s", newTarget(nodeScrapeTime, "%s"+sel, ""))
+	d.addStat("Failed Requests", "none", newTarget(nodeErrors, "increase(%s"+sel+"[$__range])", ""))
+	d.addStat("Interfaces Up", "none", newTarget(ifacePropState, "sum(%s"+sel+")", ""))
+
+	d.addRow("Interfaces")
+	d.addGraph("Input Traffic", "bps", newTarget(ifaceCounterInputBytes, withIfaceName(rate+" * 8"), "{{name}}"))
+	d.addGraph("Output Traffic", "bps", newTarget(ifaceCounterOutputBytes, withIfaceName(rate+" * 8"), "{{name}}"))
+	d.addGraph("Errors", "pps",
+		newTarget(ifaceCounterInputErrors, withIfaceName(rate+" > 0"), "{{name}} input"),
+		newTarget(ifaceCounterOutputErrors, withIfaceName(rate+" > 0"), "{{name}} output"),
+	)
+	d.addGraph("Discards", "pps",
+		newTarget(ifaceCounterInputDiscards, withIfaceName(rate+" > 0"), "{{name}} input"),
+		newTarget(ifaceCounterOutputDiscards, withIfaceName(rate+" > 0"), "{{name}} output"),
+	)
+
+	d.addRow("Optics")
+	d.addGraph("Receive Power", "dBm", newTarget(transceiverLaneRxPower, "%s"+sel, "{{iface_name}} lane {{lane_id}}"))
+	d.addGraph("Transmit Power", "dBm", newTarget(transceiverLaneTxPower, "%s"+sel, "{{iface_name}} lane {{lane_id}}"))
+	d.addGraph("Temperature", "celsius", newTarget(transceiverLaneTemperature, "%s"+sel, "{{iface_name}} lane {{lane_id}}"))
+	d.addGraph("Current", "amp", newTarget(transceiverLaneCurrent, "%s"+sel, "{{iface_name}} lane {{lane_id}}"))
+
+	d.addRow("Environment")
+	d.addGraph("Sensor Temperature", "celsius",
+		newTarget(sensorTemperature, "%s"+sel, "{{sensor}}"),
+		newTarget(sensorTemperatureThresholdHigh, "%s"+sel, "{{sensor}} threshold"),
+	)
+	d.addGraph("Power Supply Output", "watt", newTarget(powerSupplyPowerOutput, "%s"+sel, "{{power_supply}}"))
+	d.addGraph("Power Supply Status", "none", newTarget(powerSupplyUp, "%s"+sel, "{{power_supply}}"))
+	d.addGraph("Fan Status", "none", newTarget(fanUp, "%s"+sel, "{{fan}}"))
+
+	d.addRow("Resources")
+	d.addGraph("CPU", "percent",
+		newTarget(cpuUsageTotalUser, "%s"+sel, "user"),
+		newTarget(cpuUsageTotalKernel, "%s"+sel, "kernel"),
+	)
+	d.addGraph("Memory", "none",
+		newTarget(memoryUsageUsed, "%s"+sel, "used"),
+		newTarget(memoryUsageFree, "%s"+sel, "free"),
+	)
+	d.addGraph("Processes", "none",
+		newTarget(processUsageRunning, "%s"+sel, "running"),
+		newTarget(processUsageTotal, "%s"+sel, "total"),
+	)
+	return d
+}
+
+// getInterfaceDashboard returns the view of an interface of a node.
+func getInterfaceDashboard() *grafanaDashboard {
+	d := newDashboard("network-exporter-interface", "Network Exporter / Interface")
+	d.addNodeVariable(false)
+	d.addIfaceVariable()
+	sel := `{job=~"$job", node="$node", iface="$iface"}`
+	optics := `{job=~"$job", node="$node", iface_name="${iface:text}"}`
+	rate := "rate(%s" + sel + "[$__rate_interval])"
+
+	d.addRow("Status")
+	d.addStat("State", "none", newTarget(ifacePropState, "%s"+sel, ""))
+	d.addStat("Admin State", "none", newTarget(ifacePropAdminState, "%s"+sel, ""))
+	d.addStat("Speed", "Mbits", newTarget(ifacePropSpeed, "%s"+sel, ""))
+	d.addStat("MTU", "none", newTarget(ifacePropMTU, "%s"+sel, ""))
+
+	d.addRow("Traffic")
+	d.addGraph("Traffic", "bps",
+		newTarget(ifaceCounterInputBytes, rate+" * 8", "input"),
+		newTarget(ifaceCounterOutputBytes, rate+" * 8", "output"),
+	)
+	d.addGraph("Packets", "pps",
+		newTarget(ifaceCounterInputPackets, rate, "input"),
+		newTarget(ifaceCounterOutputPackets, rate, "output"),
+	)
+	d.addGraph("Errors", "pps",
+		newTarget(ifaceCounterInputErrors, rate, "input"),
+		newTarget(ifaceCounterOutputErrors, rate, "output"),
+		newTarget(ifaceCounterCrcErrors, rate, "crc"),
+	)
+	d.addGraph("Discards", "pps",
+		newTarget(ifaceCounterInputDiscards, rate, "input"),
+		newTarget(ifaceCounterOutputDiscards, rate, "output"),
+	)
+
+	d.addRow("Optics")
+	d.addGraph("Power", "dBm",
+		newTarget(transceiverLaneRxPower, "%s"+optics, "receive lane {{lane_id}}"),
+		newTarget(transceiverLaneTxPower, "%s"+optics, "transmit lane {{lane_id}}"),
+	)
+	d.addGraph("Temperature", "celsius", newTarget(transceiverLaneTemperature, "%s"+optics, "lane {{lane_id}}"))
+	return d
+}
+
+// GetDashboards returns the Grafana dashboards for the metrics produced
+// by the exporter. The keys are the file names of the dashboards.
+func GetDashboards() (map[string]string, error) {
+	dashboards := make(map[string]string)
+	for _, d := range []*grafanaDashboard{
+		getFleetDashboard(),
+		getNodeDashboard(),
+		getInterfaceDashboard(),
+	} {
+		var out strings.Builder
+		enc := json.NewEncoder(&out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(d); err != nil {
+			return nil, fmt.Errorf("error rendering dashboard %s: %s", d.UID, err)
+		}
+		dashboards[d.UID+".json"] = out.String()
+	}
+	return dashboards, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestGetDashboards(t *testing.T) {
+	dashboards, err := GetDashboards()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(dashboards) != 3 {
+		t.Fatalf("expected 3 dashboards, but got %d", len(dashboards))
+	}
+	metricRegex := regexp.MustCompile(`\bnet_[a-z_]+`)
+	for name, data := range dashboards {
+		d := &grafanaDashboard{}
+		if err := json.Unmarshal([]byte(data), d); err != nil {
+			t.Fatalf("%s: error parsing dashboard: %s", name, err)
+		}
+		if name != d.UID+".json" {
+			t.Errorf("%s: unexpected uid %q", name, d.UID)
+		}
+		ids := make(map[int]bool)
+		for _, p := range d.Panels {
+			if ids[p.ID] {
+				t.Errorf("%s: duplicate panel id %d", name, p.ID)
+			}
+			ids[p.ID] = true
+			if p.GridPos.X+p.GridPos.W > 24 {
+				t.Errorf("%s: panel %q is out of the grid", name, p.Title)
+			}
+			for _, target := range p.Targets {
+				for _, m := range metricRegex.FindAllString(target.Expr, -1) {
+					if _, exists := descInfosByName[m]; !exists {
+						t.Errorf("%s: panel %q refers to unknown metric %s", name, p.Title, m)
+					}
+				}
+			}
+		}
+	}
+}
@@ -18,114 +18,266 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// descInfo is an entry of the metric catalog. It holds the name, help,
+// type, unit, and label names of a metric descriptor, the subsystem
+// collecting the metric, and the modules supporting it. The both
+// descriptor is the variant of the descriptor used by the "both" label
+// strategy.
+type descInfo struct {
+	desc      *prometheus.Desc
+	fqName    string
+	subsystem string
+	name      string
+	help      string
+	valueType prometheus.ValueType
+	unit      string
+	labels    []string
+	collector string
+	modules   []string
+	both      *prometheus.Desc
+}
+
+var descInfos = make(map[*prometheus.Desc]*descInfo)
+
+// descInfosByName are the descriptor details by the fully-qualified
+// names of the metrics.
+var descInfosByName = make(map[string]*descInfo)
+
+// metricCatalog are the metrics produced by the exporter in the order of
+// the subsystems collecting them.
+var metricCatalog []*descInfo
+
+// newDesc returns a new metric descriptor and keeps track of its name,
+// help, type, unit, and label names. It is called during package
+// initialization only, because the descriptor details are read without
+// locking.
+func newDesc(subsystem, name, help string, valueType prometheus.ValueType, unit string, labels []string) *prometheus.Desc {
+	info := newDescInfo(subsystem, name, help, valueType, unit, labels)
+	descInfos[info.desc] = info
+	descInfosByName[info.fqName] = info
+	return info.desc
+}
+
+// newDescInfo returns the details of a new metric descriptor without
+// keeping track of them.
+func newDescInfo(subsystem, name, help string, valueType prometheus.ValueType, unit string, labels []string) *descInfo {
+	fqName := prometheus.BuildFQName(namespace, subsystem, name)
+	return &descInfo{
+		desc:      prometheus.NewDesc(fqName, help, labels, nil),
+		fqName:    fqName,
+		subsystem: subsystem,
+		name:      name,
+		help:      help,
+		valueType: valueType,
+		unit:      unit,
+		labels:    labels,
+		modules:   knownModules,
+		both:      prometheus.NewDesc(fqName, help, getLabelNames(labels, LabelStrategyBoth), nil),
+	}
+}
+
+// subsystemDescs are the metric descriptors grouped by the subsystem
+// collecting them.
+var subsystemDescs = []struct {
+	subsystem string
+	descs     []*prometheus.Desc
+}{
+	{
+		"system", []*prometheus.Desc{
+			nodeUp,
+			nodeHostname,
+			nodeErrors,
+			nodeNextScrape,
+			nodeScrapeTime,
+			nodeSystemHostname,
+			nodeSystemIdentifier,
+		},
+	},
+	{
+		"interfaces", []*prometheus.Desc{
+			ifaceName,
+			ifaceLocalIndex,
+			ifaceDescription,
+			ifaceDescriptionInfo,
+			ifaceMetricBandwidth,
+			ifaceMetricDelay,
+			ifaceMetricReliability,
+			ifaceMetricRxload,
+			ifaceMetricTxload,
+			ifaceCounterBabbles,
+			ifaceCounterBadEtherTypeDrops,
+			ifaceCounterBadProtocolDrops,
+			ifaceCounterNoCarrier,
+			ifaceCounterDribble,
+			ifaceCounterInputFrameErrors,
+			ifaceCounterInputDiscards,
+			ifaceCounterInputErrors,
+			ifaceCounterInputPause,
+			ifaceCounterInputOverruns,
+			ifaceCounterInputIfaceDownDrops,
+			ifaceCounterInputBytes,
+			ifaceCounterInputUnicastBytes,
+			ifaceCounterInputPackets,
+			ifaceCounterInputUnicastPackets,
+			ifaceCounterInputBroadcastPackets,
+			ifaceCounterInputMulticastPackets,
+			ifaceCounterInputJumboPackets,
+			ifaceCounterInputCompressed,
+			ifaceCounterInputFifo,
+			ifaceCounterLateCollisions,
+			ifaceCounterLostCarrier,
+			ifaceCounterOutputDiscards,
+			ifaceCounterOutputErrors,
+			ifaceCounterOutputPause,
+			ifaceCounterOutputUnderruns,
+			ifaceCounterOutputBytes,
+			ifaceCounterOutputUnicastBytes,
+			ifaceCounterOutputPackets,
+			ifaceCounterOutputUnicastPackets,
+			ifaceCounterOutputBroadcastPackets,
+			ifaceCounterOutputMulticastPackets,
+			ifaceCounterOutputJumboPackets,
+			ifaceCounterOutputCarrierErrors,
+			ifaceCounterCollisions,
+			ifaceCounterOutputFifo,
+			ifaceCounterWatchdog,
+			ifaceCounterStormSuppression,
+			ifaceCounterIgnored,
+			ifaceCounterRunts,
+			ifaceCounterCrcErrors,
+			ifaceCounterDeferred,
+			ifaceCounterNoBufferReceivedErrors,
+			ifaceCounterResets,
+			ifacePropBeaconEnabled,
+			ifacePropAutoNegotiationEnabled,
+			ifacePropMdixEnabled,
+			ifacePropMTU,
+			ifacePropSpeed,
+			ifacePropDuplex,
+			ifacePropEncapsulatedVlan,
+			ifacePropState,
+			ifacePropAdminState,
+			ifaceIsSubinterface,
+			ifaceIsRoutedMode,
+			ifaceIsAccessMode,
+			ifacePropIPAddress,
+			ifacePropHWAddress,
+			ifaceCountersLastCleared,
+			ifaceCountersClearings,
+			ifaceRateInputBits,
+			ifaceRateOutputBits,
+			ifaceRateUtilization,
+		},
+	},
+	{
+		"vlans", []*prometheus.Desc{
+			vlanID,
+			vlanName,
+			vlanState,
+			vlanShutdownState,
+		},
+	},
+	{
+		"environment", []*prometheus.Desc{
+			fanUp,
+			powerSupplyUp,
+			powerSupplyPowerInput,
+			powerSupplyPowerOutput,
+			powerSupplyPowerCapacity,
+			sensorUp,
+			sensorTemperature,
+			sensorTemperatureThresholdHigh,
+			sensorTemperatureThresholdLow,
+		},
+	},
+	{
+		"resources", []*prometheus.Desc{
+			processUsageRunning,
+			processUsageTotal,
+			memoryUsageTotal,
+			memoryUsageFree,
+			memoryUsageUsed,
+			cpuUsageTotalIdle,
+			cpuUsageTotalKernel,
+			cpuUsageTotalUser,
+			cpuUsagePerCPUIdle,
+			cpuUsagePerCPUKernel,
+			cpuUsagePerCPUUser,
+		},
+	},
+	{
+		"transceivers", []*prometheus.Desc{
+			transceiverUp,
+			transceiverInfo,
+			transceiverLaneTemperature,
+			transceiverLaneVoltage,
+			transceiverLaneCurrent,
+			transceiverLaneTxPower,
+			transceiverLaneRxPower,
+			transceiverLaneErrors,
+		},
+	},
+	{
+		"bgp", []*prometheus.Desc{
+			bgpSessionUp,
+			bgpSessionConnectionsDropped,
+		},
+	},
+}
+
+// descSubsystems maps metric descriptors to the subsystems collecting them.
+var descSubsystems = make(map[*prometheus.Desc]string)
+
+func init() {
+	for _, group := range subsystemDescs {
+		for _, desc := range group.descs {
+			descSubsystems[desc] = group.subsystem
+			descSubsystems[descInfos[desc].both] = group.subsystem
+			descInfos[desc].collector = group.subsystem
+			metricCatalog = append(metricCatalog, descInfos[desc])
+		}
+	}
+}
+
 // Describe describes all the metrics ever exported by the exporter. It
 // implements prometheus.Collector.
 func (n *NetworkNode) Describe(ch chan<- *prometheus.Desc) {
-	ch <- nodeUp
-	ch <- nodeHostname
-	ch <- nodeErrors
-	ch <- nodeNextScrape
-	ch <- nodeScrapeTime
-	ch <- nodeSystemHostname
-	ch <- nodeSystemIdentifier
-	ch <- ifaceName
-	ch <- ifaceLocalIndex
-	ch <- ifaceDescription
-	ch <- ifaceMetricBandwidth
-	ch <- ifaceMetricDelay
-	ch <- ifaceMetricReliability
-	ch <- ifaceMetricRxload
-	ch <- ifaceMetricTxload
-	ch <- ifaceCounterBabbles
-	ch <- ifaceCounterBadEtherTypeDrops
-	ch <- ifaceCounterBadProtocolDrops
-	ch <- ifaceCounterNoCarrier
-	ch <- ifaceCounterDribble
-	ch <- ifaceCounterInputFrameErrors
-	ch <- ifaceCounterInputDiscards
-	ch <- ifaceCounterInputErrors
-	ch <- ifaceCounterInputPause
-	ch <- ifaceCounterInputOverruns
-	ch <- ifaceCounterInputIfaceDownDrops
-	ch <- ifaceCounterInputBytes
-	ch <- ifaceCounterInputUnicastBytes
-	ch <- ifaceCounterInputPackets
-	ch <- ifaceCounterInputUnicastPackets
-	ch <- ifaceCounterInputBroadcastPackets
-	ch <- ifaceCounterInputMulticastPackets
-	ch <- ifaceCounterInputJumboPackets
-	ch <- ifaceCounterInputCompressed
-	ch <- ifaceCounterInputFifo
-	ch <- ifaceCounterLateCollisions
-	ch <- ifaceCounterLostCarrier
-	ch <- ifaceCounterOutputDiscards
-	ch <- ifaceCounterOutputErrors
-	ch <- ifaceCounterOutputPause
-	ch <- ifaceCounterOutputUnderruns
-	ch <- ifaceCounterOutputBytes
-	ch <- ifaceCounterOutputUnicastBytes
-	ch <- ifaceCounterOutputPackets
-	ch <- ifaceCounterOutputUnicastPackets
-	ch <- ifaceCounterOutputBroadcastPackets
-	ch <- ifaceCounterOutputMulticastPackets
-	ch <- ifaceCounterOutputJumboPackets
-	ch <- ifaceCounterOutputCarrierErrors
-	ch <- ifaceCounterCollisions
-	ch <- ifaceCounterOutputFifo
-	ch <- ifaceCounterWatchdog
-	ch <- ifaceCounterStormSuppression
-	ch <- ifaceCounterIgnored
-	ch <- ifaceCounterRunts
-	ch <- ifaceCounterCrcErrors
-	ch <- ifaceCounterDeferred
-	ch <- ifaceCounterNoBufferReceivedErrors
-	ch <- ifaceCounterResets
-	ch <- ifacePropBeaconEnabled
-	ch <- ifacePropAutoNegotiationEnabled
-	ch <- ifacePropMdixEnabled
-	ch <- ifacePropMTU
-	ch <- ifacePropSpeed
-	ch <- ifacePropDuplex
-	ch <- ifacePropEncapsulatedVlan
-	ch <- ifacePropState
-	ch <- ifacePropAdminState
-	ch <- ifaceIsSubinterface
-	ch <- ifaceIsRoutedMode
-	ch <- ifaceIsAccessMode
-	ch <- ifacePropIPAddress
-	ch <- ifacePropHWAddress
-	ch <- vlanID
-	ch <- vlanName
-	ch <- vlanState
-	ch <- vlanShutdownState
-
-	ch <- fanUp
-	ch <- powerSupplyUp
-	ch <- powerSupplyPowerInput
-	ch <- powerSupplyPowerOutput
-	ch <- powerSupplyPowerCapacity
-	ch <- sensorUp
-	ch <- sensorTemperature
-	ch <- sensorTemperatureThresholdHigh
-	ch <- sensorTemperatureThresholdLow
+	for _, info := range metricCatalog {
+		if desc := n.getCatalogDesc(info); desc != nil {
+			ch <- desc
+		}
+	}
+}
 
-	ch <- processUsageRunning
-	ch <- processUsageTotal
-	ch <- memoryUsageTotal
-	ch <- memoryUsageFree
-	ch <- memoryUsageUsed
-	ch <- cpuUsageTotalIdle
-	ch <- cpuUsageTotalKernel
-	ch <- cpuUsageTotalUser
-	ch <- cpuUsagePerCPUIdle
-	ch <- cpuUsagePerCPUKernel
-	ch <- cpuUsagePerCPUUser
+// getCatalogDesc returns the metric descriptor of a catalog entry matching
+// the label strategy, the interface description parser, and the interface
+// rates settings of a network node. It returns nil when the node does not
+// produce the metric.
+func (n *NetworkNode) getCatalogDesc(info *descInfo) *prometheus.Desc {
+	switch info.desc {
+	case ifaceRateInputBits, ifaceRateOutputBits, ifaceRateUtilization:
+		if !n.ifaceRates {
+			return nil
+		}
+	case ifaceDescriptionInfo:
+		if n.descrParser == nil {
+			return nil
+		}
+		return n.getDesc(n.descrParser.info.desc)
+	}
+	return n.getDesc(info.desc)
+}
 
-	ch <- transceiverUp
-	ch <- transceiverLaneTemperature
-	ch <- transceiverLaneVoltage
-	ch <- transceiverLaneCurrent
-	ch <- transceiverLaneTxPower
-	ch <- transceiverLaneRxPower
-	ch <- transceiverLaneErrors
+// getSeriesCount returns the number of the collected metrics per
+// subsystem.
+func (n *NetworkNode) getSeriesCount() map[string]int {
+	m := make(map[string]int)
+	for _, metric := range n.metrics {
+		subsystem, exists := descSubsystems[metric.Desc()]
+		if !exists {
+			subsystem = "interfaces"
+		}
+		m[subsystem]++
+	}
+	return m
 }
@@ -0,0 +1,44 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// bgp metrics
+	bgpSessionUp = newDesc(
+		"bgp", "session_up",
+		"The state of a BGP session. Values are established (1), any other state (0).",
+		prometheus.GaugeValue, "",
+		[]string{
+			"node",
+			"vrf",
+			"neighbor",
+			"remote_as",
+		},
+	)
+	bgpSessionConnectionsDropped = newDesc(
+		"bgp", "session_connections_dropped",
+		"The number of times a BGP session went down.",
+		prometheus.CounterValue, "",
+		[]string{
+			"node",
+			"vrf",
+			"neighbor",
+		},
+	)
+)
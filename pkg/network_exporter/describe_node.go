@@ -19,34 +19,39 @@ import (
 )
 
 var (
-	nodeUp = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "up"),
+	nodeUp = newDesc(
+		"node", "up",
 		"Is node up and responding to queries (1) or is it down (0).",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
-		}, nil,
+		},
 	)
-	nodeHostname = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "name"),
+	nodeHostname = newDesc(
+		"node", "name",
 		"The Ansible inventory name for the device. The value is always set to 1.",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
 			"name",
-		}, nil,
+		},
 	)
-	nodeErrors = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "failed_req_count"),
+	nodeErrors = newDesc(
+		"node", "failed_req_count",
 		"The number of failed requests for a network node.",
-		[]string{"node"}, nil,
+		prometheus.CounterValue, "",
+		[]string{"node"},
 	)
-	nodeNextScrape = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "next_poll"),
+	nodeNextScrape = newDesc(
+		"node", "next_poll",
 		"The timestamp of the next potential scrape of the node.",
-		[]string{"node"}, nil,
+		prometheus.CounterValue, "seconds",
+		[]string{"node"},
 	)
-	nodeScrapeTime = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "scrape_time"),
+	nodeScrapeTime = newDesc(
+		"node", "scrape_time",
 		"The amount of time it took to scrape the node.",
-		[]string{"node"}, nil,
+		prometheus.GaugeValue, "seconds",
+		[]string{"node"},
 	)
 )
@@ -20,20 +20,22 @@ import (
 
 var (
 	// system info metrics
-	nodeSystemHostname = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "hostname"),
+	nodeSystemHostname = newDesc(
+		"node", "hostname",
 		"The configured hostname on the device itself. The value is always set to 1.",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
 			"hostname",
-		}, nil,
+		},
 	)
-	nodeSystemIdentifier = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "id"),
+	nodeSystemIdentifier = newDesc(
+		"node", "id",
 		"The unique identifier for the physical device, e.g. a serial number. The value is always set to 1.",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
 			"id",
-		}, nil,
+		},
 	)
 )
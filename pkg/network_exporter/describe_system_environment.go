@@ -19,77 +19,86 @@ import (
 )
 
 var (
-	fanUp = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "fan_up"),
+	fanUp = newDesc(
+		"node", "fan_up",
 		"The status of a fan. 1 (up, Ok), 0 (down)",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
 			"fan",
-		}, nil,
+		},
 	)
-	powerSupplyUp = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "ps_up"),
+	powerSupplyUp = newDesc(
+		"node", "ps_up",
 		"The status of a power supply. 1 (up, Ok), 0 (down)",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
 			"power_supply",
-		}, nil,
+		},
 	)
-	powerSupplyPowerInput = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "ps_pwr_input"),
+	powerSupplyPowerInput = newDesc(
+		"node", "ps_pwr_input",
 		"The power input of a power supply.",
+		prometheus.GaugeValue, "watts",
 		[]string{
 			"node",
 			"power_supply",
-		}, nil,
+		},
 	)
-	powerSupplyPowerOutput = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "ps_pwr_output"),
+	powerSupplyPowerOutput = newDesc(
+		"node", "ps_pwr_output",
 		"The power output of a power supply.",
+		prometheus.GaugeValue, "watts",
 		[]string{
 			"node",
 			"power_supply",
-		}, nil,
+		},
 	)
-	powerSupplyPowerCapacity = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "ps_pwr_capacity"),
+	powerSupplyPowerCapacity = newDesc(
+		"node", "ps_pwr_capacity",
 		"The power capacity of a power supply.",
+		prometheus.GaugeValue, "watts",
 		[]string{
 			"node",
 			"power_supply",
-		}, nil,
+		},
 	)
 
-	sensorUp = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "sensor_up"),
+	sensorUp = newDesc(
+		"node", "sensor_up",
 		"The status of a sensor. 1 (up, Ok), 0 (down)",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
 			"sensor",
-		}, nil,
+		},
 	)
-	sensorTemperature = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "sensor_temperature"),
+	sensorTemperature = newDesc(
+		"node", "sensor_temperature",
 		"The temperature of a sensor.",
+		prometheus.GaugeValue, "celsius",
 		[]string{
 			"node",
 			"sensor",
-		}, nil,
+		},
 	)
-	sensorTemperatureThresholdHigh = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "sensor_temperature_threshold_high"),
+	sensorTemperatureThresholdHigh = newDesc(
+		"node", "sensor_temperature_threshold_high",
 		"The alarm upper threshold for the temperature of a sensor.",
+		prometheus.GaugeValue, "celsius",
 		[]string{
 			"node",
 			"sensor",
-		}, nil,
+		},
 	)
-	sensorTemperatureThresholdLow = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "sensor_temperature_threshold_low"),
+	sensorTemperatureThresholdLow = newDesc(
+		"node", "sensor_temperature_threshold_low",
 		"The alarm lower threshold for the temperature of a sensor.",
+		prometheus.GaugeValue, "celsius",
 		[]string{
 			"node",
 			"sensor",
-		}, nil,
+		},
 	)
 )
@@ -19,84 +19,95 @@ import (
 )
 
 var (
-	processUsageRunning = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "running_process_count"),
+	processUsageRunning = newDesc(
+		"node", "running_process_count",
 		"The number of running processes.",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
-		}, nil,
+		},
 	)
-	processUsageTotal = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "total_process_count"),
+	processUsageTotal = newDesc(
+		"node", "total_process_count",
 		"The number of total processes.",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
-		}, nil,
+		},
 	)
-	memoryUsageTotal = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "memory_total"),
+	memoryUsageTotal = newDesc(
+		"node", "memory_total",
 		"The amount of total memory available.",
+		prometheus.GaugeValue, "kilobytes",
 		[]string{
 			"node",
-		}, nil,
+		},
 	)
-	memoryUsageFree = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "memory_free"),
+	memoryUsageFree = newDesc(
+		"node", "memory_free",
 		"The amount of free memory available.",
+		prometheus.GaugeValue, "kilobytes",
 		[]string{
 			"node",
-		}, nil,
+		},
 	)
-	memoryUsageUsed = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "memory_used"),
+	memoryUsageUsed = newDesc(
+		"node", "memory_used",
 		"The amount of memory used.",
+		prometheus.GaugeValue, "kilobytes",
 		[]string{
 			"node",
-		}, nil,
+		},
 	)
-	cpuUsageTotalIdle = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "total_cpu_idle"),
+	cpuUsageTotalIdle = newDesc(
+		"node", "total_cpu_idle",
 		"The amount of CPU time in idle state.",
+		prometheus.GaugeValue, "percent",
 		[]string{
 			"node",
-		}, nil,
+		},
 	)
-	cpuUsageTotalKernel = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "total_cpu_kernel"),
+	cpuUsageTotalKernel = newDesc(
+		"node", "total_cpu_kernel",
 		"The amount of CPU time in kernel state.",
+		prometheus.GaugeValue, "percent",
 		[]string{
 			"node",
-		}, nil,
+		},
 	)
-	cpuUsageTotalUser = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "total_cpu_user"),
+	cpuUsageTotalUser = newDesc(
+		"node", "total_cpu_user",
 		"The amount of CPU time in user state.",
+		prometheus.GaugeValue, "percent",
 		[]string{
 			"node",
-		}, nil,
+		},
 	)
-	cpuUsagePerCPUIdle = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "cpu_idle"),
+	cpuUsagePerCPUIdle = newDesc(
+		"node", "cpu_idle",
 		"The amount of CPU time in idle state on per CPU basis.",
+		prometheus.GaugeValue, "percent",
 		[]string{
 			"node",
 			"cpu_id",
-		}, nil,
+		},
 	)
-	cpuUsagePerCPUKernel = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "cpu_kernel"),
+	cpuUsagePerCPUKernel = newDesc(
+		"node", "cpu_kernel",
 		"The amount of CPU time in kernel state on per CPU basis.",
+		prometheus.GaugeValue, "percent",
 		[]string{
 			"node",
 			"cpu_id",
-		}, nil,
+		},
 	)
-	cpuUsagePerCPUUser = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "node", "cpu_user"),
+	cpuUsagePerCPUUser = newDesc(
+		"node", "cpu_user",
 		"The amount of CPU time in user state on per CPU basis.",
+		prometheus.GaugeValue, "percent",
 		[]string{
 			"node",
 			"cpu_id",
-		}, nil,
+		},
 	)
 )
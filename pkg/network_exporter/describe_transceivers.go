@@ -19,68 +19,91 @@ import (
 )
 
 var (
-	transceiverUp = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "interface", "transceiver"),
+	transceiverUp = newDesc(
+		"interface", "transceiver",
 		"The serial number and vendor of a transceiver attached to an interface are the labels of this metric. The value of the metric is always set to 1.",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
 			"iface_name",
 			"serial",
 			"vendor",
-		}, nil,
+		},
 	)
-	transceiverLaneTemperature = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "interface", "transceiver_lane_temperature"),
+	transceiverInfo = newDesc(
+		"interface", "transceiver_info",
+		"The inventory data of a transceiver attached to an interface, e.g. part number, form factor, media type, are the labels of this metric. The value of the metric is always set to 1.",
+		prometheus.GaugeValue, "",
+		[]string{
+			"node",
+			"iface_name",
+			"part_number",
+			"revision",
+			"form_factor",
+			"media_type",
+			"wavelength",
+			"cable_length",
+			"cisco_supported",
+		},
+	)
+	transceiverLaneTemperature = newDesc(
+		"interface", "transceiver_lane_temperature",
 		"The temperature of a transceiver lane.",
+		prometheus.GaugeValue, "celsius",
 		[]string{
 			"node",
 			"iface_name",
 			"lane_id",
-		}, nil,
+		},
 	)
-	transceiverLaneVoltage = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "interface", "transceiver_lane_voltage"),
+	transceiverLaneVoltage = newDesc(
+		"interface", "transceiver_lane_voltage",
 		"The voltage of a transceiver lane.",
+		prometheus.GaugeValue, "volts",
 		[]string{
 			"node",
 			"iface_name",
 			"lane_id",
-		}, nil,
+		},
 	)
-	transceiverLaneCurrent = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "interface", "transceiver_lane_current"),
+	transceiverLaneCurrent = newDesc(
+		"interface", "transceiver_lane_current",
 		"The current of a transceiver lane.",
+		prometheus.GaugeValue, "milliamperes",
 		[]string{
 			"node",
 			"iface_name",
 			"lane_id",
-		}, nil,
+		},
 	)
-	transceiverLaneTxPower = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "interface", "transceiver_lane_tx_power"),
+	transceiverLaneTxPower = newDesc(
+		"interface", "transceiver_lane_tx_power",
 		"The transmit power of a transceiver lane.",
+		prometheus.GaugeValue, "dbm",
 		[]string{
 			"node",
 			"iface_name",
 			"lane_id",
-		}, nil,
+		},
 	)
-	transceiverLaneRxPower = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "interface", "transceiver_lane_rx_power"),
+	transceiverLaneRxPower = newDesc(
+		"interface", "transceiver_lane_rx_power",
 		"The receive power of a transceiver lane.",
+		prometheus.GaugeValue, "dbm",
 		[]string{
 			"node",
 			"iface_name",
 			"lane_id",
-		}, nil,
+		},
 	)
-	transceiverLaneErrors = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "interface", "transceiver_lane_errors"),
+	transceiverLaneErrors = newDesc(
+		"interface", "transceiver_lane_errors",
 		"The number of errors with a transceiver lane.",
+		prometheus.CounterValue, "",
 		[]string{
 			"node",
 			"iface_name",
 			"lane_id",
-		}, nil,
+		},
 	)
 )
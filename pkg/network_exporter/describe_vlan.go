@@ -20,37 +20,41 @@ import (
 
 var (
 	// vlan metrics
-	vlanID = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "vlan", "id"),
+	vlanID = newDesc(
+		"vlan", "id",
 		"The Vlan ID of a VLAN. The value is always set to 1.",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
 			"vlan",
-		}, nil,
+		},
 	)
-	vlanName = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "vlan", "name"),
+	vlanName = newDesc(
+		"vlan", "name",
 		"The name of a VLAN. The value is always set to 1.",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
 			"vlan",
 			"name",
-		}, nil,
+		},
 	)
-	vlanState = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "vlan", "state"),
+	vlanState = newDesc(
+		"vlan", "state",
 		"The state of a VLAN. Values are active (1), any other value (0).",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
 			"vlan",
-		}, nil,
+		},
 	)
-	vlanShutdownState = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, "vlan", "shutdown_state"),
+	vlanShutdownState = newDesc(
+		"vlan", "shutdown_state",
 		"The shutdown state of a VLAN. Values are noshutdown (1), any other value (0).",
+		prometheus.GaugeValue, "",
 		[]string{
 			"node",
 			"vlan",
-		}, nil,
+		},
 	)
 )
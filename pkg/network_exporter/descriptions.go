@@ -0,0 +1,126 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var descrLabelNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// descriptionParser extracts fields from interface descriptions, e.g.
+// peer=ny-sw02;port=Eth1/1;circuit=ABC123;role=uplink. Only the fields
+// in the allow-list become the labels of net_iface_description_info metric.
+type descriptionParser struct {
+	keys      []string
+	regex     *regexp.Regexp
+	delimiter string
+	info      *descInfo
+}
+
+// newDescriptionParser returns an instance of descriptionParser. When the
+// regular expression is empty, the parser splits a description into
+// key=value pairs using the delimiter. Otherwise, the named groups of the
+// regular expression are the keys.
+func newDescriptionParser(keys []string, expr, delimiter string) (*descriptionParser, error) {
+	p := &descriptionParser{
+		keys:      []string{},
+		delimiter: delimiter,
+	}
+	if p.delimiter == "" {
+		p.delimiter = ";"
+	}
+	reserved := map[string]bool{"node": true, "iface": true}
+	for label, nameLabel := range nameLabels {
+		reserved[label] = true
+		reserved[nameLabel] = true
+	}
+	seen := make(map[string]bool)
+	for _, k := range keys {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		if !descrLabelNameRegex.MatchString(k) {
+			return nil, fmt.Errorf("interface description key %q is not a valid label name", k)
+		}
+		if reserved[k] {
+			return nil, fmt.Errorf("interface description key %q is a reserved label name", k)
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		p.keys = append(p.keys, k)
+	}
+	if len(p.keys) == 0 {
+		return nil, fmt.Errorf("interface description parser has no allowed keys")
+	}
+	if expr != "" {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interface description regex %q: %s", expr, err)
+		}
+		p.regex = re
+	}
+	// the descriptor of the catalog with the allowed keys as labels. It is
+	// not kept track of, because the parser belongs to the exporter.
+	info := descInfos[ifaceDescriptionInfo]
+	p.info = newDescInfo(
+		info.subsystem, info.name, info.help,
+		info.valueType, info.unit,
+		append(append([]string{}, info.labels...), p.keys...),
+	)
+	return p, nil
+}
+
+// parse returns the values of the allowed keys found in a description.
+// The values are in the order of the keys. The second return value is
+// false when none of the allowed keys were found.
+func (p *descriptionParser) parse(s string) ([]string, bool) {
+	fields := make(map[string]string)
+	if p.regex != nil {
+		match := p.regex.FindStringSubmatch(s)
+		if match == nil {
+			return nil, false
+		}
+		for i, name := range p.regex.SubexpNames() {
+			if name == "" || i >= len(match) {
+				continue
+			}
+			fields[name] = match[i]
+		}
+	} else {
+		for _, pair := range strings.Split(s, p.delimiter) {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			fields[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	values := []string{}
+	var found bool
+	for _, k := range p.keys {
+		v, exists := fields[k]
+		if exists {
+			found = true
+		}
+		values = append(values, v)
+	}
+	return values, found
+}
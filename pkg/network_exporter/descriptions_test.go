@@ -0,0 +1,87 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescriptionParser(t *testing.T) {
+	testCases := []struct {
+		keys     []string
+		regex    string
+		descr    string
+		values   string
+		found    bool
+		hasError bool
+	}{
+		{
+			keys:   []string{"peer", "port", "role"},
+			descr:  "peer=ny-sw02;port=Eth1/1;circuit=ABC123;role=uplink",
+			values: "ny-sw02,Eth1/1,uplink",
+			found:  true,
+		},
+		{
+			keys:   []string{"peer", "circuit"},
+			descr:  "peer=ny-sw02",
+			values: "ny-sw02,",
+			found:  true,
+		},
+		{
+			keys:  []string{"peer"},
+			descr: "uplink to ny-sw02",
+			found: false,
+		},
+		{
+			keys:   []string{"peer", "port"},
+			regex:  `^to (?P<peer>\S+) (?P<port>\S+)$`,
+			descr:  "to ny-sw02 Eth1/1",
+			values: "ny-sw02,Eth1/1",
+			found:  true,
+		},
+		{
+			keys:     []string{"iface"},
+			hasError: true,
+		},
+		{
+			keys:     []string{"circuit-id"},
+			hasError: true,
+		},
+	}
+	for i, tc := range testCases {
+		p, err := newDescriptionParser(tc.keys, tc.regex, "")
+		if tc.hasError {
+			if err == nil {
+				t.Errorf("test %d: expected error, but got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", i, err)
+		}
+		values, found := p.parse(tc.descr)
+		if found != tc.found {
+			t.Errorf("test %d: expected found %t, but got %t", i, tc.found, found)
+			continue
+		}
+		if v := strings.Join(values, ","); found && v != tc.values {
+			t.Errorf("test %d: expected values %q, but got %q", i, tc.values, v)
+		}
+	}
+	if info := descInfosByName[descInfos[ifaceDescriptionInfo].fqName]; info.desc != ifaceDescriptionInfo {
+		t.Errorf("expected the parsers not to replace the catalog descriptor")
+	}
+}
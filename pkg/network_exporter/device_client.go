@@ -0,0 +1,180 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
+	"github.com/prometheus/common/log"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DeviceClient is the NX-API client used by the collectors.
+type DeviceClient interface {
+	GetSystemInfo() (*api.SysInfo, error)
+	GetInterfaces() ([]*api.Interface, error)
+	GetVlans() ([]*api.Vlan, error)
+	GetSystemEnvironment() (*api.SystemEnvironment, error)
+	GetSystemResources() (*api.SystemResources, error)
+	GetTransceivers() ([]*api.Transceiver, error)
+	GetBgpSessions() ([]*bgpSession, error)
+}
+
+var fixtureNameRegex = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// fixture is the content of a file with a device API response. The
+// response is the HTTP response body the device sent, kept verbatim when
+// it is JSON, or as a string otherwise. The error is set when the request
+// failed without a response, e.g. the connection was refused.
+type fixture struct {
+	Node      string          `json:"node"`
+	Command   string          `json:"command"`
+	Timestamp string          `json:"timestamp"`
+	Error     string          `json:"error,omitempty"`
+	Status    int             `json:"status,omitempty"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Body      string          `json:"body,omitempty"`
+}
+
+// getFixturePath returns the path to the file with the response of
+// a node to a command, e.g. fixtures/ny-sw01/show_interface.json.
+func getFixturePath(dir, node, command string) string {
+	return filepath.Join(
+		dir,
+		fixtureNameRegex.ReplaceAllString(node, "_"),
+		fixtureNameRegex.ReplaceAllString(command, "_")+".json",
+	)
+}
+
+// recordFixture writes the response of a node to a command to a fixture
+// file.
+func recordFixture(dir, node, command string, status int, body []byte, err error) {
+	f := &fixture{
+		Node:      node,
+		Command:   command,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	switch {
+	case err != nil:
+		f.Error = err.Error()
+	case json.Valid(body):
+		f.Status = status
+		f.Response = body
+	default:
+		f.Status = status
+		f.Body = string(body)
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		log.Errorf("%s: failed recording %s response: %s", node, command, err)
+		return
+	}
+	fp := getFixturePath(dir, node, command)
+	if err := os.MkdirAll(filepath.Dir(fp), 0700); err != nil {
+		log.Errorf("%s: failed recording %s response: %s", node, command, err)
+		return
+	}
+	if err := ioutil.WriteFile(fp, data, 0600); err != nil {
+		log.Errorf("%s: failed recording %s response: %s", node, command, err)
+	}
+}
+
+// replayTransport serves NX-API requests from fixture files instead of
+// contacting a device. The NX-API client parses the recorded responses
+// the same way it parses the responses of a device.
+type replayTransport struct {
+	dir  string
+	node string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	command, req, err := getRequestCommand(req)
+	if err != nil {
+		return nil, err
+	}
+	fp := getFixturePath(t.dir, t.node, command)
+	data, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixture: %s", err)
+	}
+	f := &fixture{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("error parsing fixture %s: %s", fp, err)
+	}
+	if f.Error != "" {
+		return nil, fmt.Errorf("%s", f.Error)
+	}
+	if f.Status == 0 {
+		return nil, fmt.Errorf("fixture %s has no response", fp)
+	}
+	body := []byte(f.Body)
+	contentType := "text/html"
+	if len(f.Response) > 0 {
+		body = f.Response
+		contentType = "application/json"
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{contentType}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// getDeviceClient returns the client the collectors of a node use,
+// depending on whether the exporter replays API responses. The client
+// must be closed at the end of the poll.
+func (n *NetworkNode) getDeviceClient(cli *api.Client) (*deviceClient, error) {
+	var t http.RoundTripper
+	if n.replayDir != "" {
+		t = &replayTransport{dir: n.replayDir, node: n.Name}
+	} else {
+		var err error
+		if t, err = n.getDeviceTransport(); err != nil {
+			return nil, err
+		}
+	}
+	relay, err := n.newDeviceRelay(t)
+	if err != nil {
+		return nil, err
+	}
+	return newDeviceClient(cli, relay), nil
+}
+
+// isFixtureDir checks whether a directory exists.
+func isFixtureDir(dir string) error {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", strings.TrimSuffix(dir, "/"))
+	}
+	return nil
+}
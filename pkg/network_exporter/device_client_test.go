@@ -0,0 +1,179 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509/pkix"
+	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
+	simulator "github.com/greenpau/network_exporter/pkg/nxapi_sim"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestDeviceClient(t *testing.T, n *NetworkNode) *deviceClient {
+	dc, err := n.getDeviceClient(api.NewClient())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	dc.setCredential("admin", "cisco")
+	return dc
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network_exporter")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	ts, err := simulator.NewTestServer(simulator.Options{Hostname: "ny-sw01"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer ts.Close()
+
+	rec := newTestDeviceClient(t, &NetworkNode{Name: "ny-sw01", target: ts.Host, port: ts.Port, proto: "http", recordDir: dir})
+	defer rec.Close()
+	recordedInfo, err := rec.GetSystemInfo()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ts.SetFaults(simulator.Faults{InternalError: true, Commands: []string{"show vlan"}})
+	_, recordedErr := rec.GetVlans()
+	if recordedErr == nil {
+		t.Fatalf("expected error for show vlan, but got none")
+	}
+
+	data, err := ioutil.ReadFile(getFixturePath(dir, "ny-sw01", "show version"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(string(data), `"status": 200`) || !strings.Contains(string(data), `"jsonrpc"`) {
+		t.Errorf("expected the fixture to hold the raw NX-API response, but got %s", data)
+	}
+
+	// The simulator is down, and the replay does not contact it.
+	ts.Close()
+	rpl := newTestDeviceClient(t, &NetworkNode{Name: "ny-sw01", target: "ny-sw01", replayDir: dir})
+	defer rpl.Close()
+	info, err := rpl.GetSystemInfo()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *info != *recordedInfo {
+		t.Errorf("expected replayed response %+v, but got %+v", recordedInfo, info)
+	}
+	if _, err := rpl.GetVlans(); err == nil || err.Error() != recordedErr.Error() {
+		t.Errorf("expected the recorded error %q, but got %v", recordedErr, err)
+	}
+	if _, err := rpl.GetInterfaces(); err == nil {
+		t.Errorf("expected error for missing fixture, but got none")
+	}
+}
+
+func TestDeviceClientTLSVerification(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network_exporter")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	ca := newTestCertificate(t, 1, pkix.Name{CommonName: "Test CA"}, nil)
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := ioutil.WriteFile(caFile, ca.certPEM, 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ts := httptest.NewUnstartedServer(simulator.New(simulator.Options{Hostname: "ny-sw01"}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{
+			newTestCertificate(t, 10, pkix.Name{CommonName: "localhost"}, ca).tlsCertificate(t),
+		},
+	}
+	ts.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	ts.StartTLS()
+	defer ts.Close()
+	host, port, err := net.SplitHostPort(ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	portNum, _ := strconv.Atoi(port)
+
+	testCases := []struct {
+		name      string
+		target    string
+		tlsVerify bool
+		caFile    string
+		valid     bool
+	}{
+		{"no verification", host, false, "", true},
+		{"trusted ca", host, true, caFile, true},
+		{"unknown ca", host, true, "", false},
+		{"wrong name", "ny-sw01.example.com", true, caFile, false},
+	}
+	for _, tc := range testCases {
+		n := &NetworkNode{Name: "ny-sw01", target: tc.target, port: portNum, tlsVerify: tc.tlsVerify, tlsCAFile: tc.caFile}
+		dc := newTestDeviceClient(t, n)
+		defer dc.Close()
+		if tc.target != host {
+			// The name the certificate is checked against differs from
+			// the address being dialed.
+			n.httpTransport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, ts.Listener.Addr().String())
+			}
+		}
+		_, err := dc.GetSystemInfo()
+		if tc.valid && err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+		}
+		if !tc.valid && (err == nil || !strings.Contains(err.Error(), "x509")) {
+			t.Errorf("%s: expected certificate verification error, but got %v", tc.name, err)
+		}
+	}
+}
+
+func TestDeviceTransportTimeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+	client := &http.Client{
+		Transport: &deviceTransport{base: http.DefaultTransport, node: "ny-sw01", timeout: 50 * time.Millisecond},
+	}
+	start := time.Now()
+	_, err := client.Get(ts.URL)
+	if err == nil {
+		t.Fatalf("expected timeout error, but got none")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("expected the request to be cancelled after the timeout, but it took %s", time.Since(start))
+	}
+	if kind := getErrorKind(err); kind != "timeout" {
+		t.Errorf("expected timeout error kind, but got %q: %s", kind, err)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// deviceRelay is an HTTP listener on the loopback interface between
+// the NX-API client and a network node. The NX-API client builds its own
+// HTTP transport, therefore the exporter points the client to the relay,
+// and the relay forwards the requests to the node with the transport of
+// the node. The relay lives for the duration of a poll.
+type deviceRelay struct {
+	sync.Mutex
+	listener  net.Listener
+	server    *http.Server
+	transport http.RoundTripper
+	scheme    string
+	host      string
+	errors    map[string]error
+}
+
+// newDeviceRelay starts a relay forwarding the requests to a node
+// with the provided transport.
+func (n *NetworkNode) newDeviceRelay(transport http.RoundTripper) (*deviceRelay, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("error starting device relay: %s", err)
+	}
+	r := &deviceRelay{
+		listener:  listener,
+		transport: transport,
+		scheme:    n.proto,
+		host:      strings.Trim(n.target, "[]"),
+		errors:    make(map[string]error),
+	}
+	if r.scheme == "" {
+		r.scheme = "https"
+	}
+	if n.port != 0 {
+		r.host = net.JoinHostPort(r.host, strconv.Itoa(n.port))
+	} else if strings.Contains(r.host, ":") {
+		r.host = "[" + r.host + "]"
+	}
+	r.server = &http.Server{Handler: r}
+	go r.server.Serve(listener)
+	return r, nil
+}
+
+// ServeHTTP implements http.Handler. When the transport fails, e.g.
+// the certificate of the node is not trusted or the request timed out,
+// the relay keeps the error for the device client.
+func (r *deviceRelay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	command, out, err := getRequestCommand(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	out.URL = &url.URL{
+		Scheme:   r.scheme,
+		Host:     r.host,
+		Path:     req.URL.Path,
+		RawQuery: req.URL.RawQuery,
+	}
+	out.Host = ""
+	out.RequestURI = ""
+	resp, err := r.transport.RoundTrip(out)
+	if err != nil {
+		r.Lock()
+		r.errors[command] = err
+		r.Unlock()
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// getError returns the error of the transport for a command, if any,
+// instead of the error the NX-API client reported for the response of
+// the relay.
+func (r *deviceRelay) getError(command string, err error) error {
+	r.Lock()
+	defer r.Unlock()
+	if e, exists := r.errors[command]; exists {
+		delete(r.errors, command)
+		return e
+	}
+	return err
+}
+
+// Close stops the relay.
+func (r *deviceRelay) Close() error {
+	return r.server.Close()
+}
+
+// deviceClient is the NX-API client of a node talking to the node via
+// a relay.
+type deviceClient struct {
+	cli      *api.Client
+	relay    *deviceRelay
+	username string
+	password string
+}
+
+// newDeviceClient returns a client sending the requests of the NX-API
+// client to a relay.
+func newDeviceClient(cli *api.Client, relay *deviceRelay) *deviceClient {
+	cli.SetHost("127.0.0.1")
+	cli.SetPort(relay.listener.Addr().(*net.TCPAddr).Port)
+	cli.SetProtocol("http")
+	return &deviceClient{cli: cli, relay: relay}
+}
+
+// setCredential sets the credential of the client.
+func (c *deviceClient) setCredential(username, password string) {
+	c.cli.SetUsername(username)
+	c.cli.SetPassword(password)
+	c.username = username
+	c.password = password
+}
+
+// nxapiResponse is the response to a JSON-RPC call to NX-API.
+type nxapiResponse struct {
+	Result *struct {
+		Body json.RawMessage `json:"body"`
+	} `json:"result"`
+	Error *nxapiError `json:"error"`
+}
+
+// nxapiError is the error of a JSON-RPC call to NX-API, e.g. the
+// command is not supported by the node.
+type nxapiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Msg string `json:"msg"`
+	} `json:"data"`
+}
+
+func (e *nxapiError) Error() string {
+	if msg := strings.TrimSpace(e.Data.Msg); msg != "" {
+		return fmt.Sprintf("%s: %s", e.Message, msg)
+	}
+	return e.Message
+}
+
+// isInvalidCommand checks whether the node rejected a command, e.g.
+// the feature the command belongs to is not enabled.
+func (e *nxapiError) isInvalidCommand() bool {
+	return strings.Contains(e.Data.Msg, "Invalid command")
+}
+
+// call sends a command the NX-API client does not support directly to
+// the node, and returns the body of the result.
+func (c *deviceClient) call(command string) (json.RawMessage, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "cli",
+		"params":  map[string]interface{}{"cmd": command, "version": 1},
+		"id":      1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", c.relay.scheme+"://"+c.relay.host+"/ins", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json-rpc")
+	req.SetBasicAuth(c.username, c.password)
+	resp, err := c.relay.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error running %s: %s", command, resp.Status)
+	}
+	r := &nxapiResponse{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("error parsing %s response: %s", command, err)
+	}
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	if r.Result == nil {
+		return nil, fmt.Errorf("error parsing %s response: no result", command)
+	}
+	return r.Result.Body, nil
+}
+
+// GetSystemInfo implements DeviceClient.
+func (c *deviceClient) GetSystemInfo() (*api.SysInfo, error) {
+	info, err := c.cli.GetSystemInfo()
+	return info, c.relay.getError("show version", err)
+}
+
+// GetInterfaces implements DeviceClient.
+func (c *deviceClient) GetInterfaces() ([]*api.Interface, error) {
+	ifaces, err := c.cli.GetInterfaces()
+	return ifaces, c.relay.getError("show interface", err)
+}
+
+// GetVlans implements DeviceClient.
+func (c *deviceClient) GetVlans() ([]*api.Vlan, error) {
+	vlans, err := c.cli.GetVlans()
+	return vlans, c.relay.getError("show vlan", err)
+}
+
+// GetSystemEnvironment implements DeviceClient.
+func (c *deviceClient) GetSystemEnvironment() (*api.SystemEnvironment, error) {
+	env, err := c.cli.GetSystemEnvironment()
+	return env, c.relay.getError("show environment", err)
+}
+
+// GetSystemResources implements DeviceClient.
+func (c *deviceClient) GetSystemResources() (*api.SystemResources, error) {
+	resources, err := c.cli.GetSystemResources()
+	return resources, c.relay.getError("show system resources", err)
+}
+
+// GetTransceivers implements DeviceClient.
+func (c *deviceClient) GetTransceivers() ([]*api.Transceiver, error) {
+	trs, err := c.cli.GetTransceivers()
+	return trs, c.relay.getError("show interface transceiver details", err)
+}
+
+// GetBgpSessions implements DeviceClient.
+func (c *deviceClient) GetBgpSessions() ([]*bgpSession, error) {
+	body, err := c.call("show bgp sessions")
+	if err != nil {
+		return nil, err
+	}
+	return parseBgpSessions(body)
+}
+
+// Close stops the relay of the client.
+func (c *deviceClient) Close() error {
+	return c.relay.Close()
+}
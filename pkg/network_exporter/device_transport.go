@@ -0,0 +1,183 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// commandSubsystems are the subsystems whose collectors issue NX-API
+// commands.
+var commandSubsystems = map[string]string{
+	"show version":                       "system",
+	"show interface":                     "interfaces",
+	"show vlan":                          "vlans",
+	"show environment":                   "environment",
+	"show system resources":              "resources",
+	"show interface transceiver details": "transceivers",
+	"show bgp sessions":                  "bgp",
+}
+
+// getCommandSubsystem returns the subsystem of a command, or the command
+// itself when no collector issues it.
+func getCommandSubsystem(command string) string {
+	if subsystem, exists := commandSubsystems[command]; exists {
+		return subsystem
+	}
+	return command
+}
+
+// nxapiRequest is a JSON-RPC call to NX-API, e.g. {"jsonrpc":"2.0",
+// "method":"cli","params":{"cmd":"show version","version":1},"id":1}.
+type nxapiRequest struct {
+	Params struct {
+		Cmd string `json:"cmd"`
+	} `json:"params"`
+}
+
+// getRequestCommand returns the commands of an NX-API request, and
+// a copy of the request with the body restored.
+func getRequestCommand(req *http.Request) (string, *http.Request, error) {
+	if req.Body == nil {
+		return "", req, nil
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", nil, err
+	}
+	r := req.WithContext(req.Context())
+	r.Body = ioutil.NopCloser(bytes.NewReader(data))
+	calls := []*nxapiRequest{}
+	if err := json.Unmarshal(data, &calls); err != nil {
+		call := &nxapiRequest{}
+		if err := json.Unmarshal(data, call); err != nil {
+			return "", r, nil
+		}
+		calls = append(calls, call)
+	}
+	commands := []string{}
+	for _, call := range calls {
+		commands = append(commands, strings.TrimSpace(call.Params.Cmd))
+	}
+	return strings.Join(commands, " ; "), r, nil
+}
+
+// deviceTransport is the HTTP transport of the NX-API client of a node.
+// It limits the time a request may take, keeps the raw responses of the
+// node for the debug endpoint, and writes them to fixture files in the
+// record mode.
+type deviceTransport struct {
+	base         http.RoundTripper
+	node         string
+	timeout      time.Duration
+	rawResponses *rawResponseStore
+	secrets      []string
+	recordDir    string
+}
+
+// RoundTrip implements http.RoundTripper. The timeout covers both
+// the request and the reading of the response body.
+func (t *deviceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	command, req, err := getRequestCommand(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		if t.recordDir != "" {
+			recordFixture(t.recordDir, t.node, command, 0, nil, err)
+		}
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	t.rawResponses.add(t.node, getCommandSubsystem(command), command, resp.StatusCode, body, t.secrets)
+	if t.recordDir != "" {
+		recordFixture(t.recordDir, t.node, command, resp.StatusCode, body, nil)
+	}
+	return resp, nil
+}
+
+// getHTTPTransport returns the connection pool used to reach the NX-API
+// endpoint of the node. The devices usually serve self-signed
+// certificates, and they are verified only when the node requires it,
+// against the system roots or the authorities in the CA file of the node.
+func (n *NetworkNode) getHTTPTransport() (*http.Transport, error) {
+	key := fmt.Sprintf("%t|%s|%s", n.tlsVerify, n.tlsCAFile, n.target)
+	if n.httpTransport != nil && n.httpTransportKey == key {
+		return n.httpTransport, nil
+	}
+	cfg := &tls.Config{InsecureSkipVerify: !n.tlsVerify}
+	if n.tlsVerify {
+		cfg.ServerName = strings.Trim(n.target, "[]")
+		if n.tlsCAFile != "" {
+			data, err := ioutil.ReadFile(n.tlsCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("error reading tls ca file: %s", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("tls ca file %s has no valid certificates", n.tlsCAFile)
+			}
+			cfg.RootCAs = pool
+		}
+	}
+	if n.httpTransport != nil {
+		n.httpTransport.CloseIdleConnections()
+	}
+	n.httpTransport = &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSClientConfig:     cfg,
+		MaxIdleConnsPerHost: 2,
+	}
+	n.httpTransportKey = key
+	return n.httpTransport, nil
+}
+
+// getDeviceTransport returns the HTTP transport of the NX-API client
+// of the node.
+func (n *NetworkNode) getDeviceTransport() (http.RoundTripper, error) {
+	base, err := n.getHTTPTransport()
+	if err != nil {
+		return nil, err
+	}
+	return &deviceTransport{
+		base:         base,
+		node:         n.Name,
+		timeout:      time.Duration(n.timeout) * time.Second,
+		rawResponses: n.rawResponses,
+		secrets:      n.getSecrets(),
+		recordDir:    n.recordDir,
+	}, nil
+}
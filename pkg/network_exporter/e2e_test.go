@@ -0,0 +1,163 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	simulator "github.com/greenpau/network_exporter/pkg/nxapi_sim"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSimulatedExporter returns an exporter with a single node, ny-sw01,
+// served by NX-API simulator.
+func newSimulatedExporter(t *testing.T, ts *simulator.TestServer) *Exporter {
+	ifaceFilter, err := newInterfaceFilter("", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	e := &Exporter{
+		timeout:       2,
+		labelStrategy: LabelStrategyName,
+		ifaceFilter:   ifaceFilter,
+		Modules:       map[string]bool{"cisco_nxos": true},
+		Subsystems:    map[string]bool{"interfaces": true, "transceivers": true, "vlans": true, "bgp": true, "resources": true},
+		Nodes:         make(map[string]*NetworkNode),
+		Tokens:        map[string]bool{"anonymous": true},
+		Subjects:      make(map[string]bool),
+	}
+	n, err := e.newNetworkNode("ny-sw01", map[string]string{
+		"os":             "cisco_nxos",
+		"host_overwrite": ts.Host,
+		"api_port":       strconv.Itoa(ts.Port),
+		"api_proto":      "http",
+	}, []string{"ny4-cisco"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	n.credentials = []*credential{
+		{Username: "admin", Password: "cisco123", Description: "NY switch password #2"},
+		{Username: "admin", Password: "cisco", Description: "NY switch password #1"},
+	}
+	e.Nodes[n.Name] = n
+	return e
+}
+
+func scrapeSimulatedExporter(t *testing.T, e *Exporter, query string) string {
+	server := httptest.NewServer(http.HandlerFunc(e.Scrape))
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/metrics?node=ny-sw01&module=cisco_nxos&x-token=anonymous" + query)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, but got %d: %s", http.StatusOK, resp.StatusCode, body)
+	}
+	return string(body)
+}
+
+func TestEndToEnd(t *testing.T) {
+	ts, err := simulator.NewTestServer(simulator.Options{Hostname: "ny-sw01"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer ts.Close()
+
+	testCases := []struct {
+		name       string
+		faults     simulator.Faults
+		expected   []string
+		unexpected []string
+		lastErrors []string
+	}{
+		{
+			name: "healthy switch",
+			expected: []string{
+				`net_node_up{node="ny-sw01"} 1`,
+				`net_iface_name{iface="Ethernet1/1",name="Ethernet1/1",node="ny-sw01"} 1`,
+				`net_vlan_name{name="servers",node="ny-sw01",vlan="10"} 1`,
+				`net_node_fan_up{`,
+				`net_node_memory_total{node="ny-sw01"} 1.6400084e+07`,
+				`net_interface_transceiver_info{`,
+			},
+		},
+		{
+			name:       "authentication failure",
+			faults:     simulator.Faults{AuthFailure: true},
+			expected:   []string{`net_node_up{node="ny-sw01"} 0`},
+			unexpected: []string{`net_iface_name{`},
+			lastErrors: []string{"system"},
+		},
+		{
+			name: "internal server error",
+			faults: simulator.Faults{
+				InternalError: true,
+				Commands:      []string{"show vlan"},
+			},
+			expected:   []string{`net_node_up{node="ny-sw01"} 1`, `net_iface_name{`},
+			unexpected: []string{`net_vlan_name{`},
+			lastErrors: []string{"vlans"},
+		},
+		{
+			name: "malformed json",
+			faults: simulator.Faults{
+				MalformedJSON: true,
+				Commands:      []string{"show interface"},
+			},
+			expected:   []string{`net_node_up{node="ny-sw01"} 1`, `net_vlan_name{`},
+			unexpected: []string{`net_iface_name{`},
+			lastErrors: []string{"interfaces"},
+		},
+		{
+			name:     "latency",
+			faults:   simulator.Faults{Latency: 50 * time.Millisecond},
+			expected: []string{`net_node_up{node="ny-sw01"} 1`, `net_iface_name{`},
+		},
+	}
+
+	for _, tc := range testCases {
+		ts.SetFaults(tc.faults)
+		e := newSimulatedExporter(t, ts)
+		body := scrapeSimulatedExporter(t, e, "")
+		for _, s := range tc.expected {
+			if !strings.Contains(body, s) {
+				t.Errorf("%s: expected the metrics to contain %q", tc.name, s)
+			}
+		}
+		for _, s := range tc.unexpected {
+			if strings.Contains(body, s) {
+				t.Errorf("%s: expected the metrics not to contain %q", tc.name, s)
+			}
+		}
+		lastErrors := e.Nodes["ny-sw01"].getLastErrors()
+		for _, subsystem := range tc.lastErrors {
+			if lastErrors[subsystem] == "" {
+				t.Errorf("%s: expected the last error of %s subsystem", tc.name, subsystem)
+			}
+		}
+		if len(tc.lastErrors) == 0 && e.Nodes["ny-sw01"].credentialDescription != "NY switch password #1" {
+			t.Errorf("%s: expected the second credential to be in use, but got %q", tc.name, e.Nodes["ny-sw01"].credentialDescription)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	exporterNamespace = "network_exporter"
+)
+
+var (
+	exporterRegistry = prometheus.NewRegistry()
+
+	exporterCollectionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: exporterNamespace,
+			Name:      "collection_duration_seconds",
+			Help:      "The amount of time it took to collect the data of a subsystem from a network node.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"node", "subsystem"},
+	)
+	exporterAPIRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: exporterNamespace,
+			Name:      "api_request_duration_seconds",
+			Help:      "The amount of time it took a network node to respond to an API command.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"node", "command"},
+	)
+	exporterAPIErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: exporterNamespace,
+			Name:      "api_errors_total",
+			Help:      "The number of failed API commands. The kind is one of auth, timeout, parse, http_status, or other.",
+		},
+		[]string{"node", "command", "kind"},
+	)
+	exporterConcurrentScrapes = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: exporterNamespace,
+			Name:      "concurrent_scrapes",
+			Help:      "The number of scrapes being served at the moment.",
+		},
+	)
+	exporterLockWaitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: exporterNamespace,
+			Name:      "lock_wait_duration_seconds",
+			Help:      "The amount of time a scrape waited for the lock of a network node.",
+			Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 5, 10, 30, 60},
+		},
+		[]string{"node"},
+	)
+	exporterRemoteWriteSamples = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: exporterNamespace,
+			Name:      "remote_write_samples_total",
+			Help:      "The number of samples in the push mode. The result is either sent or dropped.",
+		},
+		[]string{"result"},
+	)
+	exporterRemoteWritePending = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: exporterNamespace,
+			Name:      "remote_write_pending_samples",
+			Help:      "The number of samples waiting to be sent in the push mode.",
+		},
+	)
+	exporterRemoteWriteFailedRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: exporterNamespace,
+			Name:      "remote_write_failed_requests_total",
+			Help:      "The number of failed remote write requests in the push mode.",
+		},
+	)
+)
+
+func init() {
+	exporterRegistry.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	exporterRegistry.MustRegister(prometheus.NewGoCollector())
+	exporterRegistry.MustRegister(exporterCollectionDuration)
+	exporterRegistry.MustRegister(exporterAPIRequestDuration)
+	exporterRegistry.MustRegister(exporterAPIErrors)
+	exporterRegistry.MustRegister(exporterConcurrentScrapes)
+	exporterRegistry.MustRegister(exporterLockWaitDuration)
+	exporterRegistry.MustRegister(exporterRemoteWriteSamples)
+	exporterRegistry.MustRegister(exporterRemoteWritePending)
+	exporterRegistry.MustRegister(exporterRemoteWriteFailedRequests)
+}
+
+// ExporterMetrics serves the metrics about the exporter itself, e.g.
+// collection durations, API errors, Go runtime and process metrics.
+func (e *Exporter) ExporterMetrics(w http.ResponseWriter, r *http.Request) {
+	_, scope, authorized := e.authorize(r)
+	if !authorized || !scope.admin {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	h := promhttp.HandlerFor(exporterRegistry, promhttp.HandlerOpts{})
+	h.ServeHTTP(w, r)
+}
+
+// observeCollection records the amount of time it took to collect
+// the data of a subsystem from a network node.
+func (n *NetworkNode) observeCollection(subsystem string, start time.Time) {
+	d := time.Since(start)
+	exporterCollectionDuration.WithLabelValues(n.Name, subsystem).Observe(d.Seconds())
+	n.setLastDuration(subsystem, d)
+}
+
+// observeAPIRequest records the amount of time it took a network node
+// to respond to an API command and, if the command failed, the kind
+// of the failure.
+func (n *NetworkNode) observeAPIRequest(command string, start time.Time, err error) {
+	exporterAPIRequestDuration.WithLabelValues(n.Name, command).Observe(time.Since(start).Seconds())
+	if err != nil {
+		exporterAPIErrors.WithLabelValues(n.Name, command, getErrorKind(err)).Inc()
+	}
+}
+
+var (
+	// errorStatusCodeRegex matches a status code reported as, e.g.
+	// "status code: 503" or "status=503".
+	errorStatusCodeRegex = regexp.MustCompile(`(?i)\bstatus(?:[ _]code)?\s*[:=]?\s*([1-5][0-9]{2})\b`)
+	// errorStatusLineRegex matches a status code followed by its reason
+	// phrase, e.g. "503 Service Unavailable".
+	errorStatusLineRegex = regexp.MustCompile(`\b([1-5][0-9]{2}) ([A-Za-z][A-Za-z -]*[A-Za-z])`)
+)
+
+// getErrorStatusCode returns the HTTP status code reported in an error
+// message, or 0 when the message does not carry one.
+func getErrorStatusCode(s string) int {
+	if m := errorStatusCodeRegex.FindStringSubmatch(s); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		return code
+	}
+	for _, m := range errorStatusLineRegex.FindAllStringSubmatch(s, -1) {
+		code, _ := strconv.Atoi(m[1])
+		text := http.StatusText(code)
+		if text != "" && strings.HasPrefix(strings.ToLower(m[2]), strings.ToLower(text)) {
+			return code
+		}
+	}
+	return 0
+}
+
+// getErrorKind classifies the errors returned by API client.
+func getErrorKind(err error) string {
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return "timeout"
+	}
+	switch getErrorStatusCode(err.Error()) {
+	case 0:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "auth"
+	default:
+		return "http_status"
+	}
+	s := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(s, "unauthorized"), strings.Contains(s, "forbidden"),
+		strings.Contains(s, "authentication"):
+		return "auth"
+	case strings.Contains(s, "timeout"), strings.Contains(s, "deadline exceeded"):
+		return "timeout"
+	case strings.Contains(s, "invalid character"), strings.Contains(s, "unmarshal"),
+		strings.Contains(s, "unexpected end of json"), strings.Contains(s, "parse"):
+		return "parse"
+	}
+	return "other"
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type testTimeoutError struct{}
+
+func (e testTimeoutError) Error() string   { return "i/o timeout" }
+func (e testTimeoutError) Timeout() bool   { return true }
+func (e testTimeoutError) Temporary() bool { return true }
+
+func TestGetErrorKind(t *testing.T) {
+	testCases := []struct {
+		err  error
+		kind string
+	}{
+		{testTimeoutError{}, "timeout"},
+		{context.DeadlineExceeded, "timeout"},
+		{errors.New("show version: 401 Unauthorized"), "auth"},
+		{errors.New("response status code: 403"), "auth"},
+		{errors.New("authentication failed for admin"), "auth"},
+		{errors.New("show interface: 503 Service Unavailable"), "http_status"},
+		{errors.New("unexpected status=500"), "http_status"},
+		{errors.New("response status code: 404, body: not found"), "http_status"},
+		{errors.New("invalid character '<' looking for beginning of value"), "parse"},
+		{errors.New("json: cannot unmarshal string into Go value of type int"), "parse"},
+		{errors.New("Post https://10.1.401.1/ins: dial tcp: connection refused"), "other"},
+		{errors.New("Post http://ny-sw01/ins: EOF"), "other"},
+		{errors.New("device returned 404 interfaces"), "other"},
+		{fmt.Errorf("error polling node: %s", "connection reset by peer"), "other"},
+	}
+	for _, tc := range testCases {
+		if kind := getErrorKind(tc.err); kind != tc.kind {
+			t.Errorf("%q: expected kind %q, but got %q", tc.err, tc.kind, kind)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// interfaceFilter decides which interfaces of a network node
+// are being exported.
+type interfaceFilter struct {
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+	onlyUp  bool
+}
+
+// newInterfaceFilter returns an instance of interfaceFilter. An empty
+// include or exclude expression disables the corresponding check.
+func newInterfaceFilter(include, exclude string, onlyUp bool) (*interfaceFilter, error) {
+	f := &interfaceFilter{
+		onlyUp: onlyUp,
+	}
+	if include != "" {
+		re, err := regexp.Compile(include)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interface include filter %q: %s", include, err)
+		}
+		f.include = re
+	}
+	if exclude != "" {
+		re, err := regexp.Compile(exclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interface exclude filter %q: %s", exclude, err)
+		}
+		f.exclude = re
+	}
+	return f, nil
+}
+
+// override returns a copy of the filter with the values of the
+// exporter_iface_include, exporter_iface_exclude, and exporter_iface_only_up
+// inventory variables applied on top of it.
+func (f *interfaceFilter) override(vars map[string]string) (*interfaceFilter, error) {
+	nf := &interfaceFilter{}
+	if f != nil {
+		*nf = *f
+	}
+	if v, exists := vars["exporter_iface_include"]; exists {
+		tf, err := newInterfaceFilter(v, "", false)
+		if err != nil {
+			return nil, err
+		}
+		nf.include = tf.include
+	}
+	if v, exists := vars["exporter_iface_exclude"]; exists {
+		tf, err := newInterfaceFilter("", v, false)
+		if err != nil {
+			return nil, err
+		}
+		nf.exclude = tf.exclude
+	}
+	if v, exists := vars["exporter_iface_only_up"]; exists {
+		onlyUp, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exporter_iface_only_up value %q: %s", v, err)
+		}
+		nf.onlyUp = onlyUp
+	}
+	return nf, nil
+}
+
+// match returns true when the interface with the provided name and
+// operational state passes the filter.
+func (f *interfaceFilter) match(name, state string) bool {
+	if f == nil {
+		return true
+	}
+	if f.onlyUp && state != "up" {
+		return false
+	}
+	return f.matchName(name)
+}
+
+// matchName returns true when the interface with the provided name passes
+// the include and exclude expressions of the filter. The operational
+// state of the interface is not checked.
+func (f *interfaceFilter) matchName(name string) bool {
+	if f == nil {
+		return true
+	}
+	if f.include != nil && !f.include.MatchString(name) {
+		return false
+	}
+	if f.exclude != nil && f.exclude.MatchString(name) {
+		return false
+	}
+	return true
+}
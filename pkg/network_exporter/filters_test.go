@@ -0,0 +1,52 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import "testing"
+
+func TestInterfaceFilter(t *testing.T) {
+	global, err := newInterfaceFilter("", "^(mgmt|loopback)", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f, err := global.override(map[string]string{
+		"exporter_iface_include": "^Ethernet1/",
+		"exporter_iface_only_up": "true",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testCases := []struct {
+		name     string
+		state    string
+		expected bool
+	}{
+		{"Ethernet1/1", "up", true},
+		{"Ethernet1/2", "down", false},
+		{"Ethernet2/1", "up", false},
+		{"mgmt0", "up", false},
+	}
+	for _, tc := range testCases {
+		if v := f.match(tc.name, tc.state); v != tc.expected {
+			t.Errorf("%s (%s): expected %t, but got %t", tc.name, tc.state, tc.expected, v)
+		}
+	}
+	if !global.match("Ethernet2/1", "down") {
+		t.Errorf("expected the override not to change the global filter")
+	}
+	if _, err := global.override(map[string]string{"exporter_iface_exclude": "("}); err == nil {
+		t.Errorf("expected error for invalid regular expression, but got none")
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	dto "github.com/prometheus/client_model/go"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The output formats of the metrics page in addition to Prometheus
+// text format.
+const (
+	FormatInflux = "influx"
+	FormatJSON   = "json"
+)
+
+// formattedSample is a sample of a metric with the measurement, field,
+// and tags derived from the name and the labels of the metric.
+type formattedSample struct {
+	Measurement string            `json:"measurement"`
+	Field       string            `json:"field"`
+	Tags        map[string]string `json:"tags"`
+	Value       float64           `json:"value"`
+}
+
+// formattedMetric is a metric in JSON format.
+type formattedMetric struct {
+	Name    string             `json:"name"`
+	Help    string             `json:"help"`
+	Type    string             `json:"type"`
+	Samples []*formattedSample `json:"samples"`
+}
+
+// isValidFormat checks whether the metrics page supports a format.
+func isValidFormat(s string) bool {
+	switch s {
+	case "", "prometheus", FormatInflux, FormatJSON:
+		return true
+	}
+	return false
+}
+
+// getMeasurement returns the measurement and the field of a metric, e.g.
+// net_iface and input_bytes for net_iface_input_bytes.
+func getMeasurement(name string) (string, string) {
+	if info, exists := descInfosByName[name]; exists {
+		return namespace + "_" + info.subsystem, info.name
+	}
+	parts := strings.SplitN(name, "_", 3)
+	if len(parts) < 3 {
+		return name, "value"
+	}
+	return parts[0] + "_" + parts[1], parts[2]
+}
+
+// getFormattedMetrics returns the metrics of a node with the names of
+// the node, the interfaces, and the VLANs in the node, iface, and vlan
+// tags, regardless of the label strategy.
+func (n *NetworkNode) getFormattedMetrics(families []*dto.MetricFamily) []*formattedMetric {
+	n.RLock()
+	defer n.RUnlock()
+	metrics := []*formattedMetric{}
+	for _, mf := range families {
+		measurement, field := getMeasurement(mf.GetName())
+		m := &formattedMetric{
+			Name:    mf.GetName(),
+			Help:    mf.GetHelp(),
+			Type:    strings.ToLower(mf.GetType().String()),
+			Samples: []*formattedSample{},
+		}
+		for _, metric := range mf.GetMetric() {
+			s := &formattedSample{
+				Measurement: measurement,
+				Field:       field,
+				Tags:        make(map[string]string),
+			}
+			labels := make(map[string]bool)
+			for _, p := range metric.GetLabel() {
+				labels[p.GetName()] = true
+			}
+			for _, p := range metric.GetLabel() {
+				if isPairedNameLabel(p.GetName(), labels) {
+					// the names are in node, iface, and vlan tags
+					continue
+				}
+				s.Tags[p.GetName()] = n.getLabelName(p.GetName(), p.GetValue())
+			}
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				s.Value = metric.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				s.Value = metric.GetGauge().GetValue()
+			case dto.MetricType_UNTYPED:
+				s.Value = metric.GetUntyped().GetValue()
+			default:
+				continue
+			}
+			m.Samples = append(m.Samples, s)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// isPairedNameLabel checks whether a label carries a name under the "both"
+// label strategy, e.g. iface_name, and the identifier label it is paired
+// with, e.g. iface, is among the labels of the metric. The transceiver
+// metrics have iface_name label without iface label.
+func isPairedNameLabel(label string, labels map[string]bool) bool {
+	for id, name := range nameLabels {
+		if name == label {
+			return labels[id]
+		}
+	}
+	return false
+}
+
+// writeInflux writes the metrics in InfluxDB line protocol. The samples
+// with the same measurement and tags are the fields of a single line.
+func writeInflux(w io.Writer, metrics []*formattedMetric, ts time.Time) error {
+	lines := make(map[string][]string)
+	keys := []string{}
+	for _, m := range metrics {
+		for _, s := range m.Samples {
+			if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+				continue
+			}
+			var sb strings.Builder
+			sb.WriteString(escapeInflux(s.Measurement, ", "))
+			tags := []string{}
+			for k := range s.Tags {
+				tags = append(tags, k)
+			}
+			sort.Strings(tags)
+			for _, k := range tags {
+				if s.Tags[k] == "" {
+					continue
+				}
+				sb.WriteString("," + escapeInflux(k, ",= ") + "=" + escapeInflux(s.Tags[k], ",= "))
+			}
+			key := sb.String()
+			if _, exists := lines[key]; !exists {
+				keys = append(keys, key)
+			}
+			lines[key] = append(lines[key], escapeInflux(s.Field, ",= ")+"="+strconv.FormatFloat(s.Value, 'f', -1, 64))
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(w, "%s %s %d\n", key, strings.Join(lines[key], ","), ts.UnixNano()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeInflux escapes the characters special to InfluxDB line protocol.
+func escapeInflux(s, chars string) string {
+	for _, c := range chars {
+		s = strings.Replace(s, string(c), `\`+string(c), -1)
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	simulator "github.com/greenpau/network_exporter/pkg/nxapi_sim"
+	"strings"
+	"testing"
+)
+
+func TestOutputFormats(t *testing.T) {
+	ts, err := simulator.NewTestServer(simulator.Options{Hostname: "ny-sw01"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer ts.Close()
+	e := newSimulatedExporter(t, ts)
+	// the formats have the names regardless of the label strategy
+	e.Nodes["ny-sw01"].labelStrategy = LabelStrategyUUID
+
+	body := scrapeSimulatedExporter(t, e, "&format=influx")
+	for _, expected := range []string{
+		`net_node,node=ny-sw01 `,
+		`net_iface,iface=Ethernet1/1,node=ny-sw01 `,
+		`net_vlan,name=servers,node=ny-sw01,vlan=10 name=1 `,
+		`net_interface,iface_name=Ethernet1/1,lane_id=1,node=ny-sw01 `,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in influx output:\n%s", expected, body)
+		}
+	}
+
+	// the name labels of the "both" label strategy are dropped only when
+	// paired with an identifier, and transceivers keep iface_name
+	e.Nodes["ny-sw01"].labelStrategy = LabelStrategyBoth
+	body = scrapeSimulatedExporter(t, e, "&format=influx")
+	if !strings.Contains(body, `net_interface,iface_name=Ethernet1/1,lane_id=1,node=ny-sw01 `) {
+		t.Errorf("expected transceiver lane with iface_name tag in influx output:\n%s", body)
+	}
+	if strings.Contains(body, "node_name=") || strings.Contains(body, ",iface_name=Ethernet1/1,name=") {
+		t.Errorf("expected paired name labels to be dropped in influx output:\n%s", body)
+	}
+	e.Nodes["ny-sw01"].labelStrategy = LabelStrategyUUID
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		if strings.HasPrefix(line, "net_iface,iface=Ethernet1/1,node=ny-sw01 ") && !strings.Contains(line, "input_bytes=") {
+			t.Errorf("expected input_bytes field in the line of Ethernet1/1: %s", line)
+		}
+	}
+
+	body = scrapeSimulatedExporter(t, e, "&format=json")
+	resp := &struct {
+		Node    string             `json:"node"`
+		Metrics []*formattedMetric `json:"metrics"`
+	}{}
+	if err := json.Unmarshal([]byte(body), resp); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	found := false
+	for _, m := range resp.Metrics {
+		if m.Name != "net_iface_input_bytes" {
+			continue
+		}
+		for _, s := range m.Samples {
+			if s.Tags["iface"] == "Ethernet1/1" && s.Measurement == "net_iface" && s.Field == "input_bytes" && m.Type == "counter" {
+				found = true
+			}
+		}
+	}
+	if resp.Node != "ny-sw01" || !found {
+		t.Errorf("expected net_iface_input_bytes of Ethernet1/1 in json output:\n%s", body)
+	}
+}
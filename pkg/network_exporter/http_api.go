@@ -0,0 +1,138 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// APINodesPath is the path of the JSON status API for nodes.
+	APINodesPath = "/api/v1/nodes"
+)
+
+// secretVariableKeywords are the parts of the names of inventory
+// variables whose values are redacted by the status API.
+var secretVariableKeywords = []string{"pass", "secret", "token", "key", "community"}
+
+// NodeStatus is the status of a network node exposed by the status API.
+type NodeStatus struct {
+	Name                 string            `json:"name"`
+	Target               string            `json:"target"`
+	Result               string            `json:"result"`
+	Timestamp            string            `json:"timestamp"`
+	Module               string            `json:"module"`
+	Errors               int64             `json:"errors"`
+	NextCollectionTicker int64             `json:"nextCollectionTicker"`
+	LastErrors           map[string]string `json:"lastErrors"`
+	Credential           string            `json:"credential"`
+	Timeout              int               `json:"timeout"`
+	PollInterval         int64             `json:"pollInterval"`
+	Subsystems           string            `json:"subsystems"`
+	Variables            map[string]string `json:"variables"`
+}
+
+// getStatus returns the status of a network node.
+func (n *NetworkNode) getStatus() *NodeStatus {
+	n.errorsLocker.RLock()
+	errors := n.errors
+	n.errorsLocker.RUnlock()
+	st := &NodeStatus{
+		Name:                 n.Name,
+		Target:               n.target,
+		Result:               n.result,
+		Timestamp:            n.timestamp,
+		Module:               n.module,
+		Errors:               errors,
+		NextCollectionTicker: n.nextCollectionTicker,
+		LastErrors:           n.getLastErrors(),
+		Credential:           n.credentialDescription,
+		Timeout:              n.timeout,
+		PollInterval:         n.pollInterval,
+		Subsystems:           n.getEnabledSubsystems(),
+		Variables:            make(map[string]string),
+	}
+	for k, v := range n.Variables {
+		st.Variables[k] = redactVariable(k, v)
+	}
+	return st
+}
+
+// redactVariable returns REDACTED in place of the value of an inventory
+// variable when the variable is likely to hold a secret.
+func redactVariable(k, v string) string {
+	k = strings.ToLower(k)
+	for _, keyword := range secretVariableKeywords {
+		if strings.Contains(k, keyword) {
+			return "REDACTED"
+		}
+	}
+	return v
+}
+
+// NodeStatusAPI serves the status of the nodes in JSON format. The
+// /api/v1/nodes path returns the list of the nodes, and the
+// /api/v1/nodes/{name} path returns a single node.
+func (e *Exporter) NodeStatusAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
+	_, scope, authorized := e.authorize(r)
+	if !authorized {
+		writeJSONError(w, http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+	nodeName := strings.Trim(strings.TrimPrefix(r.URL.Path, APINodesPath), "/")
+	if nodeName != "" {
+		n, exists := e.getNode(nodeName)
+		if !exists || !scope.allowsNode(n) {
+			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("unknown node %q", nodeName))
+			return
+		}
+		writeJSON(w, http.StatusOK, n.getStatus())
+		return
+	}
+	nodes := []*NodeStatus{}
+	for _, n := range e.getNodes() {
+		if !scope.allowsNode(n) {
+			continue
+		}
+		nodes = append(nodes, n.getStatus())
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"nodes": nodes,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+	w.Write([]byte("\n"))
+}
+
+func writeJSONError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	data, _ := json.Marshal(map[string]string{"error": msg})
+	w.Write(data)
+	w.Write([]byte("\n"))
+}
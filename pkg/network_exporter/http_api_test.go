@@ -0,0 +1,77 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNodeStatusAPI(t *testing.T) {
+	e := &Exporter{
+		Nodes:  make(map[string]*NetworkNode),
+		Tokens: map[string]bool{"secret": true},
+	}
+	e.Nodes["ny-sw01"] = &NetworkNode{
+		Name:   "ny-sw01",
+		result: "success",
+		module: "cisco_nxos",
+		Variables: map[string]string{
+			"os":                "cisco_nxos",
+			"ansible_password":  "cisco",
+			"snmp_community":    "public",
+			"exporter_api_port": "443",
+		},
+	}
+	e.Nodes["ny-sw01"].setLastError("interfaces", nil)
+
+	w := httptest.NewRecorder()
+	e.NodeStatusAPI(w, httptest.NewRequest("GET", "/api/v1/nodes/ny-sw01?x-token=secret", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, but got %d", http.StatusOK, w.Code)
+	}
+	st := &NodeStatus{}
+	if err := json.Unmarshal(w.Body.Bytes(), st); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]string{
+		"os":                "cisco_nxos",
+		"ansible_password":  "REDACTED",
+		"snmp_community":    "REDACTED",
+		"exporter_api_port": "443",
+	}
+	for k, v := range expected {
+		if st.Variables[k] != v {
+			t.Errorf("variable %s: expected %q, but got %q", k, v, st.Variables[k])
+		}
+	}
+	if _, exists := st.LastErrors["interfaces"]; !exists {
+		t.Errorf("expected the last error of interfaces subsystem")
+	}
+
+	w = httptest.NewRecorder()
+	e.NodeStatusAPI(w, httptest.NewRequest("GET", "/api/v1/nodes/ny-sw02?x-token=secret", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, but got %d", http.StatusNotFound, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	e.NodeStatusAPI(w, httptest.NewRequest("GET", "/api/v1/nodes", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, but got %d", http.StatusForbidden, w.Code)
+	}
+}
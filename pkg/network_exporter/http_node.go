@@ -0,0 +1,171 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+const (
+	// NodeDetailPath is the path of the node detail page.
+	NodeDetailPath = "/node"
+)
+
+var nodeDetailTemplate = template.Must(template.New("node").Funcs(template.FuncMap{
+	"duration": func(d time.Duration) string {
+		if d == 0 {
+			return "-"
+		}
+		return d.Round(time.Millisecond).String()
+	},
+	"timestamp": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	},
+	"color": func(result string) string {
+		switch result {
+		case "success":
+			return "lightgreen"
+		case "failure":
+			return "tomato"
+		}
+		return "lightgray"
+	},
+}).Parse(`<html>
+<head><title>Prometheus Exporter for Networking: {{.Name}}</title></head>
+<body>
+<h1>{{.Name}}</h1>
+<p><a href="{{.SummaryURL}}">Summary</a> | <a href="{{.MetricsURL}}">Metrics</a></p>
+<table border='1'>
+<tr><th>Target</th><td>{{.Target}}</td></tr>
+<tr><th>Module</th><td>{{.Module}}</td></tr>
+<tr><th>Last Result</th><td style="background-color:{{color .Result}}">{{.Result}}</td></tr>
+<tr><th>Last Scrape</th><td>{{.Timestamp}}</td></tr>
+<tr><th>Errors</th><td>{{.Errors}}</td></tr>
+<tr><th>Credential</th><td>{{.Credential}}</td></tr>
+</table>
+<h2>Subsystems</h2>
+<table border='1'>
+<tr><th>Subsystem</th><th>Last Result</th><th>Last Collection</th><th>Duration</th><th>Series</th><th>Error</th></tr>
+{{range .Subsystems}}<tr><td>{{.Subsystem}}</td><td style="background-color:{{color .Result}}">{{.Result}}</td><td>{{timestamp .Timestamp}}</td><td>{{duration .Duration}}</td><td>{{.Series}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>
+<h2>Credential Attempts</h2>
+<table border='1'>
+<tr><th>Credential</th><th>Username</th><th>Result</th><th>Error</th></tr>
+{{range .CredentialAttempts}}<tr><td>{{.Description}}</td><td>{{.Username}}</td><td style="background-color:{{color .Result}}">{{.Result}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>
+<h2>Last Polls</h2>
+<table border='1'>
+<tr><th>Started</th><th>Result</th><th>Duration</th><th>Error</th></tr>
+{{range .PollHistory}}<tr><td>{{timestamp .Timestamp}}</td><td style="background-color:{{color .Result}}">{{.Result}}</td><td>{{duration .Duration}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+type nodeDetail struct {
+	Name               string
+	Target             string
+	Module             string
+	Result             string
+	Timestamp          string
+	Errors             int64
+	Credential         string
+	SummaryURL         string
+	MetricsURL         string
+	Subsystems         []*subsystemStatus
+	CredentialAttempts []*credentialAttempt
+	PollHistory        []*pollOutcome
+}
+
+// getDetail returns the data for the node detail page. The polls
+// are in reverse chronological order.
+func (n *NetworkNode) getDetail() *nodeDetail {
+	n.errorsLocker.RLock()
+	errors := n.errors
+	n.errorsLocker.RUnlock()
+	d := &nodeDetail{
+		Name:               n.Name,
+		Target:             n.target,
+		Module:             n.module,
+		Result:             n.result,
+		Timestamp:          n.timestamp,
+		Errors:             errors,
+		Credential:         n.credentialDescription,
+		Subsystems:         []*subsystemStatus{},
+		CredentialAttempts: []*credentialAttempt{},
+		PollHistory:        []*pollOutcome{},
+	}
+	n.statusLocker.RLock()
+	defer n.statusLocker.RUnlock()
+	for _, st := range n.statuses {
+		c := *st
+		d.Subsystems = append(d.Subsystems, &c)
+	}
+	sort.Slice(d.Subsystems, func(i, j int) bool {
+		return d.Subsystems[i].Subsystem < d.Subsystems[j].Subsystem
+	})
+	d.CredentialAttempts = append(d.CredentialAttempts, n.credentialAttempts...)
+	for i := len(n.pollHistory) - 1; i >= 0; i-- {
+		d.PollHistory = append(d.PollHistory, n.pollHistory[i])
+	}
+	return d
+}
+
+// NodeDetail returns the page with the collection status of a node,
+// i.e. subsystem results and errors, credential attempts, and the
+// outcomes of the last polls.
+func (e *Exporter) NodeDetail(p string, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
+	token, scope, authorized := e.authorize(r)
+	if !authorized || !scope.summary {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	n, exists := e.getNode(r.URL.Query().Get("node"))
+	if !exists || !scope.allowsNode(n) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	d := n.getDetail()
+	q := url.Values{}
+	if token != "" {
+		q.Set("x-token", token)
+	}
+	d.SummaryURL = "/?" + q.Encode()
+	q.Set("node", n.Name)
+	q.Set("module", n.module)
+	d.MetricsURL = p + "?" + q.Encode()
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := nodeDetailTemplate.Execute(w, d); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getNodeDetailURL returns the URL of the detail page of a node.
+func getNodeDetailURL(name, token string) string {
+	q := url.Values{}
+	q.Set("node", name)
+	if token != "" {
+		q.Set("x-token", token)
+	}
+	return NodeDetailPath + "?" + q.Encode()
+}
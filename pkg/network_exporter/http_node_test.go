@@ -0,0 +1,86 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNodeDetail(t *testing.T) {
+	name := "<b>ny-sw01</b>"
+	e := &Exporter{
+		Nodes:  make(map[string]*NetworkNode),
+		Tokens: map[string]bool{"secret": true},
+	}
+	n := &NetworkNode{
+		Name:   name,
+		result: "failure",
+	}
+	e.Nodes[name] = n
+	n.setLastError("interfaces", fmt.Errorf("<script>alert(1)</script>"))
+	n.setLastDuration("interfaces", 1500*time.Millisecond)
+	n.setSeriesCount(map[string]int{"interfaces": 42})
+	for i := 0; i < maxPollHistory+5; i++ {
+		n.addPollOutcome(&pollOutcome{Timestamp: time.Now(), Result: "failure"}, []*credentialAttempt{
+			{Description: "default", Username: "admin", Result: "failure", Error: "401 Unauthorized"},
+		})
+	}
+	if len(n.pollHistory) != maxPollHistory {
+		t.Errorf("expected %d polls, but got %d", maxPollHistory, len(n.pollHistory))
+	}
+
+	w := httptest.NewRecorder()
+	e.NodeDetail("/metrics", w, httptest.NewRequest("GET", getNodeDetailURL(name, "secret"), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, but got %d", http.StatusOK, w.Code)
+	}
+	body := w.Body.String()
+	for _, s := range []string{"<b>ny-sw01</b>", "<script>"} {
+		if strings.Contains(body, s) {
+			t.Errorf("expected %q to be escaped", s)
+		}
+	}
+	for _, s := range []string{"&lt;b&gt;ny-sw01&lt;/b&gt;", "1.5s", "<td>42</td>", "401 Unauthorized"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("expected the page to contain %q", s)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	name := "ny-sw01'&x-token=<b>"
+	e := &Exporter{
+		Nodes:  map[string]*NetworkNode{name: {Name: name, module: "cisco_nxos"}},
+		Tokens: map[string]bool{"secret": true},
+	}
+	w := httptest.NewRecorder()
+	e.Summary("/metrics", w, httptest.NewRequest("GET", "/?x-token=secret", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, but got %d", http.StatusOK, w.Code)
+	}
+	body := w.Body.String()
+	if strings.Contains(body, name) {
+		t.Errorf("expected %q to be escaped", name)
+	}
+	expected := "<a href='/metrics?module=cisco_nxos&amp;node=ny-sw01%27%26x-token%3D%3Cb%3E&amp;x-token=secret'>Metrics</a>"
+	if !strings.Contains(body, expected) {
+		t.Errorf("expected the page to contain %q, but got %s", expected, body)
+	}
+}
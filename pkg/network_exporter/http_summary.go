@@ -15,16 +15,18 @@
 package exporter
 
 import (
+	"html"
 	"net/http"
-	"sort"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
 // Summary returns the content of the Exporter's default page.
 func (e *Exporter) Summary(p string, w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
-	token, authorized := e.authorize(r)
-	if !authorized {
+	token, scope, authorized := e.authorize(r)
+	if !authorized || !scope.summary {
 		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 		return
 	}
@@ -39,22 +41,28 @@ func (e *Exporter) Summary(p string, w http.ResponseWriter, r *http.Request) {
 	sb.WriteString(`<th>Module</th>`)
 	sb.WriteString(`<th>Last Result</th>`)
 	sb.WriteString(`<th>Last Scrape</th>`)
+	sb.WriteString(`<th>Timeout</th>`)
+	sb.WriteString(`<th>Poll Interval</th>`)
+	sb.WriteString(`<th>Subsystems</th>`)
 	sb.WriteString(`<th>Metrics</th><tr>`)
-	if len(e.Nodes) < 1 {
+	nodes := e.getNodes()
+	if len(nodes) < 1 {
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
-	hosts := []string{}
-	for _, n := range e.Nodes {
-		hosts = append(hosts, n.Name)
-	}
-	sort.Strings(hosts)
-	for _, h := range hosts {
-		n := e.Nodes[h]
-		url := p + `?node=` + n.Name + `&module=` + n.module + `&x-token=` + token
+	for _, n := range nodes {
+		if !scope.allowsNode(n) {
+			continue
+		}
+		q := url.Values{}
+		q.Set("node", n.Name)
+		q.Set("module", n.module)
+		if token != "" {
+			q.Set("x-token", token)
+		}
 		sb.WriteString(`<tr>`)
-		sb.WriteString(`<td>` + n.Name + `</td>`)
-		sb.WriteString(`<td>` + n.module + `</td>`)
+		sb.WriteString(`<td><a href='` + html.EscapeString(getNodeDetailURL(n.Name, token)) + `'>` + html.EscapeString(n.Name) + `</a></td>`)
+		sb.WriteString(`<td>` + html.EscapeString(n.module) + `</td>`)
 		switch n.result {
 		case "success":
 			sb.WriteString(`<td style="background-color:lightgreen">` + n.result + `</td>`)
@@ -63,8 +71,11 @@ func (e *Exporter) Summary(p string, w http.ResponseWriter, r *http.Request) {
 		default:
 			sb.WriteString(`<td style="background-color:lightgray">` + n.result + `</td>`)
 		}
-		sb.WriteString(`<td>` + n.timestamp + `</td>`)
-		sb.WriteString(`<td><a href='` + url + `'>Metrics</a></td>`)
+		sb.WriteString(`<td>` + html.EscapeString(n.timestamp) + `</td>`)
+		sb.WriteString(`<td>` + strconv.Itoa(n.timeout) + `s</td>`)
+		sb.WriteString(`<td>` + strconv.FormatInt(n.pollInterval, 10) + `s</td>`)
+		sb.WriteString(`<td>` + html.EscapeString(n.getEnabledSubsystems()) + `</td>`)
+		sb.WriteString(`<td><a href='` + html.EscapeString(p+"?"+q.Encode()) + `'>Metrics</a></td>`)
 		sb.WriteString(`</tr>`)
 	}
 	sb.WriteString(`</table>`)
@@ -0,0 +1,100 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+	"time"
+)
+
+// ifaceSample holds the counters of an interface collected at a point in
+// time, and the clearings of the counters detected so far.
+type ifaceSample struct {
+	timestamp     time.Time
+	inputBytes    uint64
+	outputBytes   uint64
+	inputPackets  uint64
+	outputPackets uint64
+	lastCleared   time.Time
+	clearings     int64
+}
+
+// updateIfaceSample replaces the previous sample of an interface and
+// returns it. The second return value is true when the counters of the
+// interface went backwards since the previous sample, i.e. the counters
+// were cleared.
+func (n *NetworkNode) updateIfaceSample(ifaceID string, ts time.Time, counters *api.InterfaceCounters) (*ifaceSample, bool) {
+	if n.ifaceSamples == nil {
+		n.ifaceSamples = make(map[string]*ifaceSample)
+	}
+	sample := &ifaceSample{
+		timestamp:     ts,
+		inputBytes:    counters.InputBytes,
+		outputBytes:   counters.OutputBytes,
+		inputPackets:  counters.InputPackets,
+		outputPackets: counters.OutputPackets,
+	}
+	prev, exists := n.ifaceSamples[ifaceID]
+	n.ifaceSamples[ifaceID] = sample
+	if !exists {
+		return nil, false
+	}
+	sample.lastCleared = prev.lastCleared
+	sample.clearings = prev.clearings
+	if sample.inputBytes >= prev.inputBytes && sample.outputBytes >= prev.outputBytes &&
+		sample.inputPackets >= prev.inputPackets && sample.outputPackets >= prev.outputPackets {
+		return prev, false
+	}
+	log.Debugf("%s: interface %s counters went backwards", n.UUID, n.interfaceNames[ifaceID])
+	sample.lastCleared = ts
+	sample.clearings++
+	return prev, true
+}
+
+// addIfaceCountersCleared adds the time when the counters of an interface
+// were last seen cleared and the number of the clearings.
+func (n *NetworkNode) addIfaceCountersCleared(ifaceID string) {
+	sample := n.ifaceSamples[ifaceID]
+	var lastCleared float64
+	if !sample.lastCleared.IsZero() {
+		lastCleared = float64(sample.lastCleared.Unix())
+	}
+	n.metrics = append(n.metrics, n.newConstMetric(
+		ifaceCountersLastCleared,
+		prometheus.GaugeValue,
+		lastCleared,
+		n.UUID,
+		ifaceID,
+	))
+	n.metrics = append(n.metrics, n.newConstMetric(
+		ifaceCountersClearings,
+		prometheus.CounterValue,
+		float64(sample.clearings),
+		n.UUID,
+		ifaceID,
+	))
+}
+
+// pruneIfaceSamples removes the samples of the interfaces missing from
+// the last poll, e.g. removed or filtered out.
+func (n *NetworkNode) pruneIfaceSamples(seen map[string]bool) {
+	for ifaceID := range n.ifaceSamples {
+		if !seen[ifaceID] {
+			delete(n.ifaceSamples, ifaceID)
+		}
+	}
+}
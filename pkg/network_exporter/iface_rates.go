@@ -0,0 +1,59 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// addIfaceRates adds the input and output rates and the utilization of an
+// interface computed from its previous sample. The speed is in Mb/s. The
+// rates are not being computed on the first poll of an interface and after
+// its counters were cleared. When polls were missed, the rates are the
+// averages since the last successful poll.
+func (n *NetworkNode) addIfaceRates(ifaceID string, prev, sample *ifaceSample, speed float64) {
+	elapsed := sample.timestamp.Sub(prev.timestamp).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	rates := []struct {
+		desc      *prometheus.Desc
+		direction string
+		value     float64
+	}{
+		{ifaceRateInputBits, "input", float64(sample.inputBytes-prev.inputBytes) * 8 / elapsed},
+		{ifaceRateOutputBits, "output", float64(sample.outputBytes-prev.outputBytes) * 8 / elapsed},
+	}
+	for _, rate := range rates {
+		n.metrics = append(n.metrics, n.newConstMetric(
+			rate.desc,
+			prometheus.GaugeValue,
+			rate.value,
+			n.UUID,
+			ifaceID,
+		))
+		if speed <= 0 {
+			continue
+		}
+		n.metrics = append(n.metrics, n.newConstMetric(
+			ifaceRateUtilization,
+			prometheus.GaugeValue,
+			rate.value/(speed*1000000),
+			n.UUID,
+			ifaceID,
+			rate.direction,
+		))
+	}
+}
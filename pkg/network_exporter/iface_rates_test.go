@@ -0,0 +1,111 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	api "github.com/greenpau/go-cisco-nx-api/pkg/client"
+	dto "github.com/prometheus/client_model/go"
+	"testing"
+	"time"
+)
+
+func TestIfaceRates(t *testing.T) {
+	n := &NetworkNode{
+		UUID:           "ny-sw01",
+		labelStrategy:  LabelStrategyName,
+		interfaceNames: map[string]string{},
+		ifaceRates:     true,
+		ifaceSamples:   make(map[string]*ifaceSample),
+	}
+	start := time.Now()
+	testCases := []struct {
+		name     string
+		elapsed  time.Duration
+		input    uint64
+		output   uint64
+		speed    float64
+		expected map[string]float64
+	}{
+		{"first poll", 0, 1000, 1000, 1, map[string]float64{}},
+		{"second poll", 10 * time.Second, 13500, 3500, 1, map[string]float64{
+			"net_iface_input_bits_per_second":    10000,
+			"net_iface_output_bits_per_second":   2000,
+			"net_iface_utilization_ratio input":  0.01,
+			"net_iface_utilization_ratio output": 0.002,
+		}},
+		{"cleared counters", 20 * time.Second, 500, 500, 1, map[string]float64{
+			"net_iface_counters_cleared_total": 1,
+		}},
+		{"missed polls", 50 * time.Second, 30500, 500, 0, map[string]float64{
+			"net_iface_input_bits_per_second":  8000,
+			"net_iface_output_bits_per_second": 0,
+			"net_iface_counters_cleared_total": 1,
+		}},
+	}
+	for _, tc := range testCases {
+		n.metrics = n.metrics[:0]
+		prev, cleared := n.updateIfaceSample("Ethernet1/1", start.Add(tc.elapsed), &api.InterfaceCounters{
+			InputBytes:  tc.input,
+			OutputBytes: tc.output,
+		})
+		n.addIfaceCountersCleared("Ethernet1/1")
+		if prev != nil && !cleared {
+			n.addIfaceRates("Ethernet1/1", prev, n.ifaceSamples["Ethernet1/1"], tc.speed)
+		}
+		values := make(map[string]float64)
+		for _, metric := range n.metrics {
+			m := &dto.Metric{}
+			if err := metric.Write(m); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			key := descInfos[metric.Desc()].fqName
+			for _, l := range m.Label {
+				if l.GetName() == "direction" {
+					key += " " + l.GetValue()
+				}
+			}
+			switch key {
+			case "net_iface_counters_last_cleared_timestamp":
+				continue
+			case "net_iface_counters_cleared_total":
+				if m.GetCounter().GetValue() == 0 {
+					continue
+				}
+				values[key] = m.GetCounter().GetValue()
+			default:
+				values[key] = m.GetGauge().GetValue()
+			}
+		}
+		if len(values) != len(tc.expected) {
+			t.Errorf("%s: expected %v, but got %v", tc.name, tc.expected, values)
+			continue
+		}
+		for k, v := range tc.expected {
+			if got, exists := values[k]; !exists || got != v {
+				t.Errorf("%s: expected %s %v, but got %v", tc.name, k, v, values)
+			}
+		}
+	}
+
+	cleared := n.ifaceSamples["Ethernet1/1"].lastCleared
+	if !cleared.Equal(start.Add(20 * time.Second)) {
+		t.Errorf("expected the counters to be last cleared on the third poll, but got %s", cleared)
+	}
+
+	n.pruneIfaceSamples(map[string]bool{})
+	if len(n.ifaceSamples) != 0 {
+		t.Errorf("expected the samples of removed interfaces to be pruned")
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	ansible "github.com/greenpau/go-ansible-db/pkg/db"
+	"github.com/prometheus/common/log"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// inventoryHost is a network node in an inventory.
+type inventoryHost struct {
+	Name      string
+	Variables map[string]string
+	Groups    []string
+}
+
+// inventoryProvider is a source of network nodes.
+type inventoryProvider interface {
+	getHosts() ([]*inventoryHost, error)
+}
+
+// ansibleInventoryProvider reads the network nodes from Ansible inventory:
+// an INI or YAML file, or a dynamic inventory script. The group_vars and
+// host_vars directories next to the inventory add variables.
+type ansibleInventoryProvider struct {
+	inventory *ansible.Inventory
+	file      string
+}
+
+func (p *ansibleInventoryProvider) getHosts() ([]*inventoryHost, error) {
+	varsDir := filepath.Dir(p.file)
+	if isAnsibleInventoryScript(p.file) {
+		data, err := runAnsibleInventoryScript(p.file, 60*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		inv, err := parseAnsibleScriptInventory(data)
+		if err != nil {
+			return nil, err
+		}
+		return inv.getHosts(varsDir)
+	}
+	data, err := ioutil.ReadFile(p.file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading inventory: %s", err)
+	}
+	inv, err := parseAnsibleYAMLInventory(data)
+	switch strings.ToLower(filepath.Ext(p.file)) {
+	case ".yml", ".yaml", ".json":
+		if err != nil {
+			return nil, err
+		}
+		return inv.getHosts(varsDir)
+	default:
+		if err == nil {
+			return inv.getHosts(varsDir)
+		}
+	}
+	// the file is in INI format
+	if err := p.inventory.LoadFromFile(p.file); err != nil {
+		return nil, fmt.Errorf("error reading inventory: %s", err)
+	}
+	items, err := p.inventory.GetHosts()
+	if err != nil {
+		return nil, fmt.Errorf("error getting hosts from the inventory: %s", err)
+	}
+	return getAnsibleINIHosts(data, items, varsDir)
+}
+
+// getAnsibleINIHosts returns the hosts of INI inventory with the variables
+// in group_vars and host_vars directories. The group_vars apply in the
+// same order as for the other inventory formats, i.e. by the depth of
+// the groups, which the INI inventory parser does not expose.
+func getAnsibleINIHosts(data []byte, items []*ansible.InventoryHost, varsDir string) ([]*inventoryHost, error) {
+	inv := parseAnsibleINIGroups(data)
+	parents := inv.getParents()
+	hosts := []*inventoryHost{}
+	for _, h := range items {
+		// the variables of INI inventory already have the group variables
+		// merged, and take precedence over group_vars
+		vars := make(map[string]string)
+		ordered := inv.getHostGroups(h.Name, parents)
+		// the groups missing from the structure have unknown depth, and
+		// apply before the others
+		missing := []string{}
+		for _, group := range h.Groups {
+			if group != "all" && !stringInSlice(group, ordered) {
+				missing = append(missing, group)
+			}
+		}
+		sort.Strings(missing)
+		groups := append(append([]string{"all"}, missing...), ordered...)
+		for _, group := range groups {
+			if err := loadAnsibleVarsFiles(vars, varsDir, "group_vars", group); err != nil {
+				return nil, err
+			}
+		}
+		for k, v := range h.Variables {
+			vars[k] = v
+		}
+		if err := loadAnsibleVarsFiles(vars, varsDir, "host_vars", h.Name); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, &inventoryHost{
+			Name:      h.Name,
+			Variables: vars,
+			Groups:    h.Groups,
+		})
+	}
+	return hosts, nil
+}
+
+// updateInventory reads the network nodes and their credentials. The
+// nodes, whose variables, groups, and credentials did not change, keep
+// their state. The nodes no longer in the inventory are removed.
+func (e *Exporter) updateInventory() error {
+	hosts, err := e.inventory.getHosts()
+	if err != nil {
+		return err
+	}
+	if len(hosts) < 1 {
+		return fmt.Errorf("the inventory has no hosts")
+	}
+	providers, err := e.getCredentialProviders(hosts)
+	if err != nil {
+		return err
+	}
+	nodes := make(map[string]*NetworkNode)
+	for _, h := range hosts {
+		if _, exists := nodes[h.Name]; exists {
+			continue
+		}
+		n, err := e.newNetworkNode(h.Name, h.Variables, h.Groups)
+		if err != nil {
+			log.Debugf("The host '%s' was not added to exporter because %s", h.Name, err)
+			continue
+		}
+		// the replay mode does not contact devices, and credentials are optional
+		if len(providers) > 0 || getAnsibleCredential(n.Variables) != nil {
+			if err := n.setCredentials(providers); err != nil {
+				return err
+			}
+		}
+		if current, exists := e.getNode(h.Name); exists && current.isSameNode(n) {
+			n = current
+		}
+		nodes[h.Name] = n
+	}
+	if len(nodes) < 1 {
+		return fmt.Errorf("the inventory has no supported hosts")
+	}
+	e.Lock()
+	for name := range e.Nodes {
+		if _, exists := nodes[name]; !exists {
+			log.Debugf("The host '%s' was removed from exporter because it is no longer in the inventory", name)
+			e.rawResponses.removeNode(name)
+		}
+	}
+	e.Nodes = nodes
+	e.Unlock()
+	return nil
+}
+
+// setCredentials sets the credentials of a network node, in the order
+// of the precedence. The credential set by ansible_user and
+// ansible_password variables precedes the credentials from providers.
+func (n *NetworkNode) setCredentials(providers []credentialProvider) error {
+	entries := []*credentialEntry{}
+	for _, p := range providers {
+		creds, err := p.getCredentials(n.Name)
+		if err != nil {
+			return fmt.Errorf("error getting credentials for host %s: %s", n.Name, err)
+		}
+		entries = append(entries, creds...)
+	}
+	sortCredentials(entries, n.Name)
+	// the credential of the host in the inventory comes first
+	if c := getAnsibleCredential(n.Variables); c != nil {
+		entries = append([]*credentialEntry{c}, entries...)
+	}
+	n.credentials = []*credential{}
+	for _, c := range entries {
+		nc := &credential{
+			Username:    c.Username,
+			Password:    c.Password,
+			Description: c.Description,
+			Failed:      false,
+		}
+		n.credentials = append(n.credentials, nc)
+	}
+	return nil
+}
+
+// isSameNode checks whether a network node has the same variables,
+// groups, and credentials as another node.
+func (n *NetworkNode) isSameNode(other *NetworkNode) bool {
+	if !reflect.DeepEqual(n.Variables, other.Variables) || !reflect.DeepEqual(n.groups, other.groups) {
+		return false
+	}
+	if len(n.credentials) != len(other.credentials) {
+		return false
+	}
+	for i, c := range n.credentials {
+		o := other.credentials[i]
+		if c.Username != o.Username || c.Password != o.Password || c.Description != o.Description {
+			return false
+		}
+	}
+	return true
+}
+
+// getNode returns a network node by its name.
+func (e *Exporter) getNode(name string) (*NetworkNode, bool) {
+	e.RLock()
+	defer e.RUnlock()
+	n, exists := e.Nodes[name]
+	return n, exists
+}
+
+// getNodes returns the network nodes sorted by their names.
+func (e *Exporter) getNodes() []*NetworkNode {
+	e.RLock()
+	defer e.RUnlock()
+	nodes := []*NetworkNode{}
+	for _, n := range e.Nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
+
+// RefreshInventory reads the inventory and the credentials periodically.
+// When a refresh fails, the exporter keeps the nodes from the previous one.
+func (e *Exporter) RefreshInventory(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		for range time.Tick(interval) {
+			if err := e.updateInventory(); err != nil {
+				log.Errorf("failed to refresh inventory: %s", err)
+				continue
+			}
+			log.Debugf("refreshed inventory, %d nodes", len(e.getNodes()))
+		}
+	}()
+}
@@ -0,0 +1,150 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// LabelStrategyUUID is the label strategy where the node, iface, and
+	// vlan labels are SHA1-derived identifiers.
+	LabelStrategyUUID = "uuid"
+	// LabelStrategyName is the label strategy where the node, iface, and
+	// vlan labels are the inventory name of a node, the name of an
+	// interface, and the ID of a VLAN.
+	LabelStrategyName = "name"
+	// LabelStrategyBoth is the label strategy where the node, iface, and
+	// vlan labels are SHA1-derived identifiers, and the node_name,
+	// iface_name, and vlan_id labels carry the corresponding names.
+	LabelStrategyBoth = "both"
+)
+
+// nameLabels are the labels holding SHA1-derived identifiers and the
+// labels holding the corresponding names under the "both" label strategy.
+var nameLabels = map[string]string{
+	"node":  "node_name",
+	"iface": "iface_name",
+	"vlan":  "vlan_id",
+}
+
+// validateLabelStrategy returns an error when a label strategy is not
+// supported.
+func validateLabelStrategy(s string) error {
+	switch s {
+	case LabelStrategyUUID, LabelStrategyName, LabelStrategyBoth:
+		return nil
+	}
+	return fmt.Errorf("unsupported label strategy %q, supported: %s, %s, %s",
+		s, LabelStrategyUUID, LabelStrategyName, LabelStrategyBoth,
+	)
+}
+
+// getLabelNames returns the label names of a metric descriptor for
+// a particular label strategy.
+func getLabelNames(labels []string, strategy string) []string {
+	if strategy != LabelStrategyBoth {
+		return labels
+	}
+	names := []string{}
+	for _, label := range labels {
+		names = append(names, label)
+		if nameLabel, exists := nameLabels[label]; exists {
+			names = append(names, nameLabel)
+		}
+	}
+	return names
+}
+
+// SetLabelStrategy sets the label strategy of a network node.
+func (n *NetworkNode) SetLabelStrategy(s string) error {
+	if err := validateLabelStrategy(s); err != nil {
+		return err
+	}
+	n.labelStrategy = s
+	return nil
+}
+
+// getDesc returns the metric descriptor matching the label strategy of
+// a network node.
+func (n *NetworkNode) getDesc(desc *prometheus.Desc) *prometheus.Desc {
+	if n.labelStrategy != LabelStrategyBoth {
+		return desc
+	}
+	if info, exists := n.getDescInfo(desc); exists {
+		return info.both
+	}
+	return desc
+}
+
+// getDescInfo returns the details of a metric descriptor, including the
+// descriptor of the interface description parser of a network node.
+func (n *NetworkNode) getDescInfo(desc *prometheus.Desc) (*descInfo, bool) {
+	if n.descrParser != nil && desc == n.descrParser.info.desc {
+		return n.descrParser.info, true
+	}
+	info, exists := descInfos[desc]
+	return info, exists
+}
+
+// getLabelName returns the name associated with a SHA1-derived identifier
+// found in the node, iface, or vlan label.
+func (n *NetworkNode) getLabelName(label, value string) string {
+	switch label {
+	case "node":
+		if value == n.UUID {
+			return n.Name
+		}
+	case "iface":
+		if name, exists := n.interfaceNames[value]; exists {
+			return name
+		}
+	case "vlan":
+		if name, exists := n.vlanNames[value]; exists {
+			return name
+		}
+	}
+	return value
+}
+
+// newConstMetric returns a constant metric. The label values are in the
+// order of the label names of the metric descriptor, and the node, iface,
+// and vlan label values are SHA1-derived identifiers. The values are
+// translated according to the label strategy of the network node.
+func (n *NetworkNode) newConstMetric(desc *prometheus.Desc, valueType prometheus.ValueType, value float64, labelValues ...string) prometheus.Metric {
+	info, exists := n.getDescInfo(desc)
+	if !exists || n.labelStrategy == "" || n.labelStrategy == LabelStrategyUUID {
+		return prometheus.MustNewConstMetric(desc, valueType, value, labelValues...)
+	}
+	values := []string{}
+	for i, label := range info.labels {
+		if i >= len(labelValues) {
+			break
+		}
+		v := labelValues[i]
+		if _, isNameLabel := nameLabels[label]; !isNameLabel {
+			values = append(values, v)
+			continue
+		}
+		switch n.labelStrategy {
+		case LabelStrategyName:
+			values = append(values, n.getLabelName(label, v))
+		case LabelStrategyBoth:
+			values = append(values, v, n.getLabelName(label, v))
+		}
+	}
+	return prometheus.MustNewConstMetric(n.getDesc(desc), valueType, value, values...)
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"strings"
+	"testing"
+)
+
+func TestLabelStrategy(t *testing.T) {
+	testCases := []struct {
+		strategy string
+		labels   string
+	}{
+		{LabelStrategyUUID, "iface=ifuuid,name=Ethernet1/1,node=nodeuuid"},
+		{LabelStrategyName, "iface=Ethernet1/1,name=Ethernet1/1,node=ny-sw01"},
+		{LabelStrategyBoth, "iface=ifuuid,iface_name=Ethernet1/1,name=Ethernet1/1,node=nodeuuid,node_name=ny-sw01"},
+	}
+	for _, tc := range testCases {
+		n := &NetworkNode{
+			Name:           "ny-sw01",
+			UUID:           "nodeuuid",
+			interfaceNames: map[string]string{"ifuuid": "Ethernet1/1"},
+		}
+		if err := n.SetLabelStrategy(tc.strategy); err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.strategy, err)
+		}
+		m := n.newConstMetric(ifaceName, prometheus.GaugeValue, 1, n.UUID, "ifuuid", "Ethernet1/1")
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.strategy, err)
+		}
+		labels := []string{}
+		for _, lp := range pb.GetLabel() {
+			labels = append(labels, lp.GetName()+"="+lp.GetValue())
+		}
+		if v := strings.Join(labels, ","); v != tc.labels {
+			t.Errorf("%s: expected labels %q, but got %q", tc.strategy, tc.labels, v)
+		}
+	}
+	n := &NetworkNode{}
+	if err := n.SetLabelStrategy("sha1"); err == nil {
+		t.Errorf("expected error for unsupported label strategy, but got none")
+	}
+}
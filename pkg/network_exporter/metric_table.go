@@ -16,65 +16,21 @@ package exporter
 
 import (
 	"fmt"
-	"github.com/prometheus/client_golang/prometheus"
-	"sort"
 	"strings"
 )
 
-// GetMetricsTable returns markdown-formatted table with the name, help, and
-// labels of the metrics produced by the exporter.
+// GetMetricsTable returns markdown-formatted table with the name, help,
+// type, unit, and labels of the metrics produced by the exporter.
 func (n *NetworkNode) GetMetricsTable() string {
 	var out strings.Builder
-	metrics := make(chan *prometheus.Desc, 100000)
-	n.Describe(metrics)
-	close(metrics)
-	out.WriteString("| **Metric** | **Description** | **Labels** |\n")
-	out.WriteString("| ------ | ------- | ------ |\n")
-	for metric := range metrics {
-		m := strings.Split(strings.Replace(metric.String(), "Desc{", "", 1), ":")
-		descr := make(map[string]string)
-		var k string
-		for _, e := range m {
-			if k == "variableLabels" {
-				descr[k] = strings.TrimSpace(e)
-				descr[k] = strings.Trim(descr[k], "}")
-				descr[k] = strings.Trim(descr[k], "]")
-				descr[k] = strings.Trim(descr[k], "[")
-
-				break
-			}
-			switch {
-			case strings.HasSuffix(e, "fqName"):
-				k = "name"
-			case strings.HasSuffix(e, ", help"):
-				descr[k] = strings.Replace(e, ", help", "", 1)
-				descr[k] = strings.TrimSpace(descr[k])
-				descr[k] = strings.Trim(descr[k], `"`)
-				k = "help"
-			case strings.HasSuffix(e, ", constLabels"):
-				descr[k] = strings.Replace(e, ", constLabels", "", 1)
-				descr[k] = strings.TrimSpace(descr[k])
-				descr[k] = strings.Trim(descr[k], `"`)
-				k = "constLabels"
-			case strings.HasSuffix(e, ", variableLabels"):
-				descr[k] = strings.Replace(e, ", variableLabels", "", 1)
-				descr[k] = strings.TrimSpace(descr[k])
-				descr[k] = strings.Trim(descr[k], `"`)
-				k = "variableLabels"
-			default:
-				out.WriteString(e + "\n")
-			}
-		}
-
-		labels := []string{}
-		for _, label := range strings.Split(descr[k], " ") {
-			if label == "" {
-				continue
-			}
-			labels = append(labels, label)
+	out.WriteString("| **Metric** | **Description** | **Type** | **Unit** | **Labels** |\n")
+	out.WriteString("| ------ | ------- | ------ | ------ | ------ |\n")
+	for _, m := range n.getMetricInfos() {
+		unit := m.Unit
+		if unit == "" {
+			unit = "-"
 		}
-		sort.Strings(labels)
-		out.WriteString(fmt.Sprintf("`%s` | %s | `%s` |\n", descr["name"], descr["help"], strings.Join(labels, "`, `")))
+		out.WriteString(fmt.Sprintf("`%s` | %s | %s | %s | `%s` |\n", m.Name, m.Help, m.Type, unit, strings.Join(m.Labels, "`, `")))
 	}
 	return out.String()
 }
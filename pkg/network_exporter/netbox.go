@@ -0,0 +1,229 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NetboxOptions are the settings of NetBox inventory. The devices
+// matching all the filters are the network nodes.
+type NetboxOptions struct {
+	// The URL of NetBox, e.g. https://netbox.example.com.
+	URL string `yaml:"url"`
+	// The API token of NetBox.
+	Token string `yaml:"token"`
+	// The slugs of the sites, device roles, and tags, and the statuses,
+	// e.g. active, of the devices.
+	Sites    []string `yaml:"sites"`
+	Roles    []string `yaml:"roles"`
+	Tags     []string `yaml:"tags"`
+	Statuses []string `yaml:"statuses"`
+}
+
+// netboxPlatforms are the exporter modules for NetBox platform slugs.
+// The other slugs become modules by replacing dashes with underscores.
+var netboxPlatforms = map[string]string{
+	"nxos":       "cisco_nxos",
+	"nx-os":      "cisco_nxos",
+	"cisco-nxos": "cisco_nxos",
+	"eos":        "arista_eos",
+	"arista-eos": "arista_eos",
+}
+
+// netboxCustomFields are the custom fields of NetBox devices exported as
+// inventory variables.
+var netboxCustomFields = []string{"api_port", "api_proto"}
+
+type netboxRef struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type netboxDevice struct {
+	Name   string `json:"name"`
+	Status struct {
+		Value string `json:"value"`
+	} `json:"status"`
+	Platform *netboxRef `json:"platform"`
+	Site     *netboxRef `json:"site"`
+	// NetBox 3.6 renamed device_role to role
+	Role       *netboxRef  `json:"role"`
+	DeviceRole *netboxRef  `json:"device_role"`
+	Tags       []netboxRef `json:"tags"`
+	PrimaryIP  *struct {
+		Address string `json:"address"`
+	} `json:"primary_ip"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
+}
+
+type netboxDeviceList struct {
+	Count   int             `json:"count"`
+	Next    string          `json:"next"`
+	Results []*netboxDevice `json:"results"`
+}
+
+// netboxInventoryProvider reads the network nodes from NetBox REST API.
+type netboxInventoryProvider struct {
+	opts   NetboxOptions
+	client *http.Client
+}
+
+func newNetboxInventoryProvider(opts NetboxOptions) (*netboxInventoryProvider, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid netbox url %q", opts.URL)
+	}
+	p := &netboxInventoryProvider{
+		opts:   opts,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+	return p, nil
+}
+
+// getDevicesURL returns the URL of the first page of the devices.
+func (p *netboxInventoryProvider) getDevicesURL() string {
+	q := url.Values{}
+	for _, v := range p.opts.Sites {
+		q.Add("site", v)
+	}
+	for _, v := range p.opts.Roles {
+		q.Add("role", v)
+	}
+	for _, v := range p.opts.Tags {
+		q.Add("tag", v)
+	}
+	for _, v := range p.opts.Statuses {
+		q.Add("status", v)
+	}
+	q.Set("limit", "1000")
+	return strings.TrimSuffix(p.opts.URL, "/") + "/api/dcim/devices/?" + q.Encode()
+}
+
+func (p *netboxInventoryProvider) getDeviceList(u string) (*netboxDeviceList, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating netbox request: %s", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.opts.Token != "" {
+		req.Header.Set("Authorization", "Token "+p.opts.Token)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error reading netbox devices: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading netbox devices: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error reading netbox devices: %s", resp.Status)
+	}
+	list := &netboxDeviceList{}
+	if err := json.Unmarshal(body, list); err != nil {
+		return nil, fmt.Errorf("error parsing netbox devices: %s", err)
+	}
+	return list, nil
+}
+
+func (p *netboxInventoryProvider) getHosts() ([]*inventoryHost, error) {
+	hosts := []*inventoryHost{}
+	visited := make(map[string]bool)
+	for u := p.getDevicesURL(); u != ""; {
+		if visited[u] {
+			return nil, fmt.Errorf("error reading netbox devices: pagination loop at %s", u)
+		}
+		visited[u] = true
+		list, err := p.getDeviceList(u)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range list.Results {
+			if d.Name == "" {
+				continue
+			}
+			hosts = append(hosts, d.getInventoryHost())
+		}
+		u = list.Next
+	}
+	return hosts, nil
+}
+
+// getInventoryHost returns the inventory host for a NetBox device. The
+// groups of the host are site_<slug>, role_<slug>, and tag_<slug>.
+func (d *netboxDevice) getInventoryHost() *inventoryHost {
+	h := &inventoryHost{
+		Name:      d.Name,
+		Variables: make(map[string]string),
+		Groups:    []string{},
+	}
+	if d.Platform != nil && d.Platform.Slug != "" {
+		if module, exists := netboxPlatforms[d.Platform.Slug]; exists {
+			h.Variables["os"] = module
+		} else {
+			h.Variables["os"] = strings.Replace(d.Platform.Slug, "-", "_", -1)
+		}
+	}
+	if d.PrimaryIP != nil && d.PrimaryIP.Address != "" {
+		// the address has prefix length, e.g. 10.1.1.1/24, and IPv6
+		// addresses are bracketed to be usable in URLs
+		addr := strings.SplitN(d.PrimaryIP.Address, "/", 2)[0]
+		if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+			addr = "[" + addr + "]"
+		}
+		h.Variables["host_overwrite"] = addr
+	}
+	for _, k := range netboxCustomFields {
+		switch v := d.CustomFields[k].(type) {
+		case string:
+			if v != "" {
+				h.Variables[k] = v
+			}
+		case float64:
+			h.Variables[k] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	if d.Status.Value != "" {
+		h.Variables["netbox_status"] = d.Status.Value
+	}
+	if d.Site != nil && d.Site.Slug != "" {
+		h.Variables["netbox_site"] = d.Site.Slug
+		h.Groups = append(h.Groups, "site_"+d.Site.Slug)
+	}
+	role := d.Role
+	if role == nil {
+		role = d.DeviceRole
+	}
+	if role != nil && role.Slug != "" {
+		h.Variables["netbox_role"] = role.Slug
+		h.Groups = append(h.Groups, "role_"+role.Slug)
+	}
+	for _, t := range d.Tags {
+		if t.Slug != "" {
+			h.Groups = append(h.Groups, "tag_"+t.Slug)
+		}
+	}
+	return h
+}
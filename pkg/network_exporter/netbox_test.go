@@ -0,0 +1,172 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// netboxTestPages are the files with the pages of the devices endpoint,
+// by the offset parameter. The test changes them while the server reads
+// them.
+type netboxTestPages struct {
+	sync.Mutex
+	files map[string]string
+}
+
+func (p *netboxTestPages) set(files map[string]string) {
+	p.Lock()
+	defer p.Unlock()
+	p.files = files
+}
+
+func (p *netboxTestPages) get(offset string) (string, bool) {
+	p.Lock()
+	defer p.Unlock()
+	file, exists := p.files[offset]
+	return file, exists
+}
+
+// newNetboxTestServer returns the stand-in for NetBox serving the
+// recorded responses of the devices endpoint.
+func newNetboxTestServer(t *testing.T, pages *netboxTestPages) *httptest.Server {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token 0123456789abcdef" {
+			writeJSONError(w, http.StatusForbidden, "Invalid token")
+			return
+		}
+		if r.URL.Path != "/api/dcim/devices/" {
+			writeJSONError(w, http.StatusNotFound, "Not found")
+			return
+		}
+		if r.URL.Query().Get("site") != "ny4" || r.URL.Query().Get("status") != "active" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		page, exists := pages.get(r.URL.Query().Get("offset"))
+		if !exists {
+			writeJSONError(w, http.StatusNotFound, "Not found")
+			return
+		}
+		data, err := ioutil.ReadFile("testdata/netbox/" + page)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(strings.Replace(string(data), "{{URL}}", ts.URL, -1)))
+	}))
+	return ts
+}
+
+func TestNetboxInventory(t *testing.T) {
+	pages := &netboxTestPages{files: map[string]string{"": "devices_page1.json", "2": "devices_page2.json"}}
+	ts := newNetboxTestServer(t, pages)
+	defer ts.Close()
+	p, err := newNetboxInventoryProvider(NetboxOptions{
+		URL:      ts.URL,
+		Token:    "0123456789abcdef",
+		Sites:    []string{"ny4"},
+		Statuses: []string{"active"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	hosts, err := p.getHosts()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(hosts) != 3 {
+		t.Fatalf("expected 3 hosts, but got %d", len(hosts))
+	}
+	expected := map[string]string{
+		"os":             "cisco_nxos",
+		"host_overwrite": "10.1.1.1",
+		"api_port":       "8443",
+		"api_proto":      "https",
+		"netbox_site":    "ny4",
+		"netbox_role":    "leaf",
+		"netbox_status":  "active",
+	}
+	if !reflect.DeepEqual(hosts[0].Variables, expected) {
+		t.Errorf("expected variables %v, but got %v", expected, hosts[0].Variables)
+	}
+	if groups := []string{"site_ny4", "role_leaf", "tag_prod"}; !reflect.DeepEqual(hosts[0].Groups, groups) {
+		t.Errorf("expected groups %v, but got %v", groups, hosts[0].Groups)
+	}
+	if hosts[1].Variables["os"] != "cisco_nxos" || hosts[1].Variables["netbox_role"] != "spine" {
+		t.Errorf("unexpected variables of ny-sw02: %v", hosts[1].Variables)
+	}
+	if _, exists := hosts[1].Variables["host_overwrite"]; exists {
+		t.Errorf("expected no target for ny-sw02 without primary ip")
+	}
+
+	// the refresh adds, removes, and keeps nodes
+	e := &Exporter{
+		Modules:   map[string]bool{"cisco_nxos": true},
+		Nodes:     make(map[string]*NetworkNode),
+		inventory: p,
+		credentials: CredentialOptions{
+			Command: `echo {"credentials":[{"default":true,"username":"admin","password":"cisco"}]}`,
+		},
+	}
+	if err := e.updateInventory(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(e.Nodes) != 2 {
+		t.Fatalf("expected 2 nodes, but got %d", len(e.Nodes))
+	}
+	n, _ := e.getNode("ny-sw01")
+	if n.target != "10.1.1.1" || n.port != 8443 || n.proto != "https" || len(n.credentials) != 1 {
+		t.Errorf("unexpected node: target %s, port %d, proto %s", n.target, n.port, n.proto)
+	}
+	pages.set(map[string]string{"": "devices_page2.json"})
+	if err := e.updateInventory(); err == nil {
+		t.Errorf("expected error for the inventory without supported hosts")
+	}
+	pages.set(map[string]string{"": "devices_page1.json", "2": "devices_page2.json"})
+	if err := e.updateInventory(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if current, _ := e.getNode("ny-sw01"); current != n {
+		t.Errorf("expected unchanged node to keep its state")
+	}
+}
+
+func TestNetboxPrimaryIP(t *testing.T) {
+	testCases := []struct {
+		address string
+		target  string
+	}{
+		{"10.1.1.1/24", "10.1.1.1"},
+		{"2001:db8::1/64", "[2001:db8::1]"},
+	}
+	for _, tc := range testCases {
+		d := &netboxDevice{Name: "ny-sw01"}
+		d.PrimaryIP = &struct {
+			Address string `json:"address"`
+		}{Address: tc.address}
+		if v := d.getInventoryHost().Variables["host_overwrite"]; v != tc.target {
+			t.Errorf("%s: expected host_overwrite %q, but got %q", tc.address, tc.target, v)
+		}
+	}
+}
@@ -49,15 +49,28 @@ type Exporter struct {
 	sync.RWMutex
 	timeout       int
 	pollInterval  int64
+	labelStrategy string
+	ifaceFilter   *interfaceFilter
+	descrParser   *descriptionParser
+	ifaceRates    bool
 	InventoryFile string
 	VaultFile     string
 	VaultKeyFile  string
 	Inventory     *ansible.Inventory
+	inventory     inventoryProvider
 	Vault         *ansible.Vault
 	Modules       map[string]bool
 	Subsystems    map[string]bool
 	Nodes         map[string]*NetworkNode
 	Tokens        map[string]bool
+	Subjects      map[string]bool
+	tokenStore    *tokenStore
+	rawResponses  *rawResponseStore
+	recordDir     string
+	replayDir     string
+	config        *Config
+	settings      *NodeSettings
+	credentials   CredentialOptions
 }
 
 // Options are the options for the initialization of an instance of the
@@ -67,6 +80,40 @@ type Options struct {
 	InventoryFile string
 	VaultFile     string
 	VaultKeyFile  string
+	LabelStrategy string
+	IfaceInclude  string
+	IfaceExclude  string
+	IfaceOnlyUp   bool
+	// The allow-listed keys of interface descriptions. When empty,
+	// the interface descriptions are not being parsed.
+	IfaceDescrKeys      []string
+	IfaceDescrRegex     string
+	IfaceDescrDelimiter string
+	// Enables the interface rates computed by the exporter, i.e. the
+	// input and output bits per second and the utilization.
+	IfaceRates bool
+	// The number of the last device responses kept per node and
+	// subsystem for the raw device response debug endpoint. Zero
+	// disables the endpoint.
+	RawResponses int
+	// The directory where the device API responses are being written
+	// to. The files are organized by node and command.
+	RecordDir string
+	// The directory with the files written in the record mode. When set,
+	// the collectors read the files instead of contacting devices.
+	ReplayDir string
+	// The configuration file with per-module, per-group, and per-node
+	// collection settings.
+	Config *Config
+	// The collection settings passed explicitly on the command line,
+	// i.e. the timeout and the poll interval. They take precedence over
+	// the configuration file.
+	Settings *NodeSettings
+	// The credential sources in addition to Ansible Vault.
+	Credentials CredentialOptions
+	// The settings of NetBox inventory. When set, the exporter reads the
+	// network nodes from NetBox instead of the inventory file.
+	Netbox *NetboxOptions
 }
 
 // NewExporter returns an initialized Exporter.
@@ -76,8 +123,37 @@ func NewExporter(opts Options) (*Exporter, error) {
 	version.Branch = gitBranch
 	version.BuildUser = buildUser
 	version.BuildDate = buildDate
+	if opts.LabelStrategy == "" {
+		opts.LabelStrategy = LabelStrategyUUID
+	}
+	if err := validateLabelStrategy(opts.LabelStrategy); err != nil {
+		return nil, err
+	}
+	ifaceFilter, err := newInterfaceFilter(opts.IfaceInclude, opts.IfaceExclude, opts.IfaceOnlyUp)
+	if err != nil {
+		return nil, err
+	}
+	var descrParser *descriptionParser
+	if len(opts.IfaceDescrKeys) > 0 {
+		descrParser, err = newDescriptionParser(opts.IfaceDescrKeys, opts.IfaceDescrRegex, opts.IfaceDescrDelimiter)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.RecordDir != "" && opts.ReplayDir != "" {
+		return nil, fmt.Errorf("record and replay modes are mutually exclusive")
+	}
+	if opts.ReplayDir != "" {
+		if err := isFixtureDir(opts.ReplayDir); err != nil {
+			return nil, fmt.Errorf("error reading replay directory: %s", err)
+		}
+	}
 	e := Exporter{
 		timeout:       opts.Timeout,
+		labelStrategy: opts.LabelStrategy,
+		ifaceFilter:   ifaceFilter,
+		descrParser:   descrParser,
+		ifaceRates:    opts.IfaceRates,
 		InventoryFile: opts.InventoryFile,
 		VaultFile:     opts.VaultFile,
 		VaultKeyFile:  opts.VaultKeyFile,
@@ -85,114 +161,152 @@ func NewExporter(opts Options) (*Exporter, error) {
 		Subsystems:    make(map[string]bool),
 		Nodes:         make(map[string]*NetworkNode),
 		Tokens:        make(map[string]bool),
+		Subjects:      make(map[string]bool),
+		rawResponses:  newRawResponseStore(opts.RawResponses),
+		recordDir:     opts.RecordDir,
+		replayDir:     opts.ReplayDir,
+		config:        opts.Config,
+		settings:      opts.Settings,
+		credentials:   opts.Credentials,
 		Inventory:     ansible.NewInventory(),
 		Vault:         ansible.NewVault(),
 	}
+	if opts.Netbox != nil && opts.Netbox.URL != "" {
+		e.inventory, err = newNetboxInventoryProvider(*opts.Netbox)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		e.inventory = &ansibleInventoryProvider{inventory: e.Inventory, file: e.InventoryFile}
+	}
 	e.Modules["cisco_nxos"] = true
 	e.Subsystems["interfaces"] = true   // interfaces
 	e.Subsystems["transceivers"] = true // fiber optics
 	e.Subsystems["vlans"] = true        // VLANs
+	e.Subsystems["environment"] = true  // fans, power supplies, and sensors
 	e.Subsystems["bgp"] = true          // BGP
 	e.Subsystems["resources"] = true    // CPU and Memory
 	if err := e.updateInventory(); err != nil {
 		return nil, err
 	}
-	for _, n := range e.Nodes {
-		if n.timeout == 0 {
-			n.timeout = e.timeout
-		}
-	}
 	log.Debugf("NewExporter() initialized successfully")
 	return &e, nil
 }
 
-func (e *Exporter) updateInventory() error {
-	if err := e.Inventory.LoadFromFile(e.InventoryFile); err != nil {
-		return fmt.Errorf("error reading inventory: %s", err)
-	}
-	if err := e.Vault.LoadPasswordFromFile(e.VaultKeyFile); err != nil {
-		return fmt.Errorf("error reading vault key file: %s", err)
-	}
-	if err := e.Vault.LoadFromFile(e.VaultFile); err != nil {
-		return fmt.Errorf("error reading vault: %s", err)
+// getCredentialProviders returns the sources of the credentials. Ansible
+// Vault is optional when other sources are configured, when the hosts
+// have credentials in the inventory, or when the exporter replays
+// recorded responses.
+func (e *Exporter) getCredentialProviders(hosts []*inventoryHost) ([]credentialProvider, error) {
+	providers := []credentialProvider{}
+	if e.VaultFile != "" {
+		if err := e.Vault.LoadPasswordFromFile(e.VaultKeyFile); err != nil {
+			return nil, fmt.Errorf("error reading vault key file: %s", err)
+		}
+		if err := e.Vault.LoadFromFile(e.VaultFile); err != nil {
+			return nil, fmt.Errorf("error reading vault: %s", err)
+		}
+		providers = append(providers, &ansibleVaultProvider{vault: e.Vault})
 	}
-	hosts, err := e.Inventory.GetHosts()
+	others, err := newCredentialProviders(e.credentials)
 	if err != nil {
-		return fmt.Errorf("error getting hosts from the inventory: %s", err)
+		return nil, fmt.Errorf("error reading credentials: %s", err)
 	}
-	if len(hosts) < 1 {
-		return fmt.Errorf("the inventory has no hosts")
+	providers = append(providers, others...)
+	if len(providers) > 0 || e.replayDir != "" {
+		return providers, nil
 	}
 	for _, h := range hosts {
-		if nos, exists := h.Variables["os"]; !exists {
-			log.Debugf("The host '%s' was not added to exporter because it lacks 'os' atribute", h.Name)
-			continue
-		} else {
-			if _, supported := e.Modules[nos]; !supported {
-				log.Debugf("The host '%s' was not added to exporter because 'os' atribute value '%s' is unsupported", h.Name, nos)
-				continue
-			}
-		}
-		if _, exists := e.Nodes[h.Name]; !exists {
-			hash := sha1.New()
-			hash.Write([]byte(h.Name))
-			n := &NetworkNode{
-				Name:                 h.Name,
-				UUID:                 fmt.Sprintf("%x", hash.Sum(nil)),
-				result:               "unknown",
-				module:               "unknown",
-				timestamp:            "unknown",
-				nextCollectionTicker: 0,
-				errors:               0,
-				Variables:            make(map[string]string),
-				credentials:          []*credential{},
-				Interfaces:           make(map[string]string),
-				Vlans:                make(map[string]string),
-			}
-			for k, v := range h.Variables {
-				n.Variables[k] = v
-			}
-			if nos, exists := n.Variables["os"]; exists {
-				n.module = nos
-			}
-			if target, exists := n.Variables["host_overwrite"]; exists {
-				n.target = target
-			} else {
-				n.target = h.Name
-			}
-			if apiPort, exists := n.Variables["api_port"]; exists {
-				if i, err := strconv.Atoi(apiPort); err == nil {
-					n.port = i
-				}
-			}
-			if apiProto, exists := n.Variables["api_proto"]; exists {
-				if apiProto == "http" || apiProto == "https" {
-					n.proto = apiProto
-				} else {
-					log.Debugf("The host '%s' was not added to exporter because 'api_proto' atribute value '%s' is unsupported", h.Name, apiProto)
-					continue
-				}
-			}
-			e.Nodes[h.Name] = n
+		if getAnsibleCredential(h.Variables) != nil {
+			return providers, nil
 		}
 	}
+	return nil, fmt.Errorf("error reading credentials: no credential sources configured")
+}
 
-	for _, n := range e.Nodes {
-		creds, err := e.Vault.GetCredentials(n.Name)
-		if err != nil {
-			return fmt.Errorf("error getting credentials for host %s: %s", n.Name, err)
+// newNetworkNode returns a network node for an inventory host. The
+// variables of the host configure the node.
+func (e *Exporter) newNetworkNode(name string, vars map[string]string, groups []string) (*NetworkNode, error) {
+	vars = resolveAnsibleVariables(vars)
+	nos, exists := vars["os"]
+	if !exists {
+		return nil, fmt.Errorf("it lacks 'os' atribute")
+	}
+	if _, supported := e.Modules[nos]; !supported {
+		return nil, fmt.Errorf("'os' atribute value '%s' is unsupported", nos)
+	}
+	hash := sha1.New()
+	hash.Write([]byte(name))
+	n := &NetworkNode{
+		Name:                 name,
+		UUID:                 fmt.Sprintf("%x", hash.Sum(nil)),
+		result:               "unknown",
+		module:               "unknown",
+		timestamp:            "unknown",
+		nextCollectionTicker: 0,
+		errors:               0,
+		timeout:              e.timeout,
+		Variables:            make(map[string]string),
+		credentials:          []*credential{},
+		Interfaces:           make(map[string]string),
+		Vlans:                make(map[string]string),
+		interfaceNames:       make(map[string]string),
+		vlanNames:            make(map[string]string),
+		labelStrategy:        e.labelStrategy,
+		descrParser:          e.descrParser,
+		ifaceRates:           e.ifaceRates,
+		ifaceSamples:         make(map[string]*ifaceSample),
+		rawResponses:         e.rawResponses,
+		recordDir:            e.recordDir,
+		replayDir:            e.replayDir,
+	}
+	for k, v := range vars {
+		n.Variables[k] = v
+	}
+	n.groups = append(n.groups, groups...)
+	n.module = nos
+	if target, exists := n.Variables["host_overwrite"]; exists {
+		n.target = target
+	} else {
+		n.target = name
+	}
+	if apiPort, exists := n.Variables["api_port"]; exists {
+		if i, err := strconv.Atoi(apiPort); err == nil {
+			n.port = i
+		}
+	}
+	if apiProto, exists := n.Variables["api_proto"]; exists {
+		if apiProto == "http" || apiProto == "https" {
+			n.proto = apiProto
+		} else {
+			return nil, fmt.Errorf("'api_proto' atribute value '%s' is unsupported", apiProto)
 		}
-		n.credentials = []*credential{}
-		for _, c := range creds {
-			nc := &credential{
-				Username: c.Username,
-				Password: c.Password,
-				Failed:   false,
-			}
-			n.credentials = append(n.credentials, nc)
+	}
+	if labelStrategy, exists := n.Variables["exporter_label_strategy"]; exists {
+		if err := n.SetLabelStrategy(labelStrategy); err != nil {
+			return nil, fmt.Errorf("'exporter_label_strategy' atribute value '%s' is unsupported", labelStrategy)
 		}
 	}
-	return nil
+	ifaceFilter, err := e.ifaceFilter.override(n.Variables)
+	if err != nil {
+		return nil, fmt.Errorf("'exporter_iface_*' atribute error: %s", err)
+	}
+	n.ifaceFilter = ifaceFilter
+	n.applySettings(e.config.getNodeSettings(n.Name, n.module, n.groups))
+	if e.settings != nil {
+		n.applySettings(e.settings)
+	}
+	// the inventory variables take precedence over the configuration file
+	// and the arguments
+	settings, err := getVariableSettings(n.Variables)
+	if err != nil {
+		return nil, err
+	}
+	n.applySettings(settings)
+	if n.pollInterval == 0 {
+		n.pollInterval = e.pollInterval
+	}
+	return n, nil
 }
 
 // GetVersionInfo returns exporter info.
@@ -223,7 +337,7 @@ func GetExporterName() string {
 // SetPollInterval sets exporter's minimal polling/scraping interval.
 func (e *Exporter) SetPollInterval(i int64) {
 	e.pollInterval = i
-	for _, n := range e.Nodes {
+	for _, n := range e.getNodes() {
 		if n.pollInterval == 0 {
 			n.pollInterval = i
 		}
@@ -237,7 +351,8 @@ func (e *Exporter) GetPollInterval() int64 {
 
 // Scrape scrapes individual nodes.
 func (e *Exporter) Scrape(w http.ResponseWriter, r *http.Request) {
-	if _, authorized := e.authorize(r); !authorized {
+	_, scope, authorized := e.authorize(r)
+	if !authorized {
 		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 		return
 	}
@@ -253,11 +368,16 @@ func (e *Exporter) Scrape(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "node parameter is required", http.StatusBadRequest)
 		return
 	}
-	node, exists := e.Nodes[nodeName]
+	node, exists := e.getNode(nodeName)
 	if !exists {
 		http.Error(w, fmt.Sprintf("unknown node %q", nodeName), http.StatusBadRequest)
 		return
 	}
+	if !scope.allowsNode(node) {
+		log.Warnf("unauthorized access to node %q from %q with token %q", node.Name, r.RemoteAddr, scope.name)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
 	moduleName := r.URL.Query().Get("module")
 	if moduleName == "" {
 		node.result = "failure"
@@ -288,15 +408,44 @@ func (e *Exporter) Scrape(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	subsystems := strings.Split(subsystemName, ",")
+	format := r.URL.Query().Get("format")
+	if !isValidFormat(format) {
+		http.Error(w, fmt.Sprintf("unsupported format %q, supported: prometheus, %s, %s", format, FormatInflux, FormatJSON), http.StatusBadRequest)
+		return
+	}
 
+	exporterConcurrentScrapes.Inc()
+	defer exporterConcurrentScrapes.Dec()
 	log.Debugf("%s: calls Scrape() for node '%s' and module '%s'", node.UUID, node.Name, moduleName)
 	start := time.Now()
 	registry := prometheus.NewRegistry()
 	node.module = moduleName
 	node.subsystems = subsystems
 	registry.MustRegister(node)
-	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
-	h.ServeHTTP(w, r)
+	switch format {
+	case FormatInflux, FormatJSON:
+		families, err := registry.Gather()
+		if err != nil {
+			log.Errorf("%s: Scrape() for node '%s' failed to gather metrics: %s", node.UUID, node.Name, err)
+		}
+		metrics := node.getFormattedMetrics(families)
+		if format == FormatJSON {
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"node":      node.Name,
+				"module":    moduleName,
+				"timestamp": start.Format(time.RFC3339),
+				"metrics":   metrics,
+			})
+			break
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := writeInflux(w, metrics, start); err != nil {
+			log.Debugf("%s: Scrape() for node '%s' failed to write response: %s", node.UUID, node.Name, err)
+		}
+	default:
+		h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+		h.ServeHTTP(w, r)
+	}
 	duration := time.Since(start).Seconds()
 	log.Debugf(
 		"%s: Scrape() for node '%s', module '%s', subsystems '%s' took %f seconds",
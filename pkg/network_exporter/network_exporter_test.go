@@ -18,9 +18,9 @@ import "testing"
 
 func TestNewExporter(t *testing.T) {
 	pollTimeout := 2
-	apiInventory := "../../assets/ansible/hosts"
-	apiVault := "../../assets/ansible/vault.yml"
-	apiVaultKey := "../../assets/ansible/vault.key"
+	apiInventory := "../../assets/demo/default/ansible/hosts"
+	apiVault := "../../assets/demo/default/ansible/vault.yml"
+	apiVaultKey := "../../assets/demo/default/ansible/vault.key"
 	opts := Options{
 		Timeout:       pollTimeout,
 		InventoryFile: apiInventory,
@@ -15,41 +15,68 @@
 package exporter
 
 import (
+	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
 type credential struct {
-	Username string
-	Password string
-	Failed   bool
+	Username    string
+	Password    string
+	Description string
+	Failed      bool
 }
 
 // NetworkNode is an instance of a managed network node, e.g. a router or switch.
 type NetworkNode struct {
 	sync.RWMutex
-	Name                 string
-	UUID                 string
-	Variables            map[string]string
-	Interfaces           map[string]string
-	Vlans                map[string]string
-	target               string
-	port                 int
-	proto                string
-	credentials          []*credential
-	result               string
-	module               string
-	timestamp            string
-	pollInterval         int64
-	timeout              int
-	errors               int64
-	errorsLocker         sync.RWMutex
-	nextCollectionTicker int64
-	metrics              []prometheus.Metric
-	subsystems           []string
+	Name                  string
+	UUID                  string
+	Variables             map[string]string
+	groups                []string
+	Interfaces            map[string]string
+	Vlans                 map[string]string
+	interfaceNames        map[string]string
+	vlanNames             map[string]string
+	labelStrategy         string
+	ifaceFilter           *interfaceFilter
+	descrParser           *descriptionParser
+	ifaceRates            bool
+	ifaceSamples          map[string]*ifaceSample
+	target                string
+	port                  int
+	proto                 string
+	credentials           []*credential
+	credentialDescription string
+	result                string
+	module                string
+	timestamp             string
+	pollInterval          int64
+	timeout               int
+	errors                int64
+	errorsLocker          sync.RWMutex
+	statuses              map[string]*subsystemStatus
+	credentialAttempts    []*credentialAttempt
+	pollHistory           []*pollOutcome
+	statusLocker          sync.RWMutex
+	nextCollectionTicker  int64
+	metrics               []prometheus.Metric
+	rawResponses          *rawResponseStore
+	httpTransport         *http.Transport
+	httpTransportKey      string
+	recordDir             string
+	replayDir             string
+	subsystems            []string
+	enabledSubsystems     map[string]bool
+	tlsVerify             bool
+	tlsCAFile             string
 }
 
 // IncrementErrorCounter increases the counter of failed queries
@@ -60,6 +87,84 @@ func (n *NetworkNode) IncrementErrorCounter() {
 	atomic.AddInt64(&n.errors, 1)
 }
 
+// applySettings applies the collection settings from the configuration
+// file to the node.
+func (n *NetworkNode) applySettings(s *NodeSettings) {
+	if s.Timeout > 0 {
+		n.timeout = s.Timeout
+	}
+	if s.PollInterval > 0 {
+		n.pollInterval = s.PollInterval
+	}
+	if len(s.Subsystems) > 0 {
+		n.enabledSubsystems = make(map[string]bool)
+		for _, v := range s.Subsystems {
+			n.enabledSubsystems[v] = true
+		}
+	}
+	if s.TLSVerify != nil {
+		n.tlsVerify = *s.TLSVerify
+	}
+	if s.TLSCAFile != "" {
+		n.tlsCAFile = s.TLSCAFile
+	}
+}
+
+// getVariableSettings returns the collection settings of a node from its
+// inventory variables: exporter_timeout, exporter_poll_interval, and
+// exporter_subsystems, i.e. a comma-separated list.
+func getVariableSettings(vars map[string]string) (*NodeSettings, error) {
+	s := &NodeSettings{}
+	if v, exists := vars["exporter_timeout"]; exists {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 1 {
+			return nil, fmt.Errorf("'exporter_timeout' atribute value '%s' is invalid", v)
+		}
+		s.Timeout = i
+	}
+	if v, exists := vars["exporter_poll_interval"]; exists {
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || i < 1 {
+			return nil, fmt.Errorf("'exporter_poll_interval' atribute value '%s' is invalid", v)
+		}
+		s.PollInterval = i
+	}
+	if v, exists := vars["exporter_subsystems"]; exists {
+		for _, subsystem := range strings.Split(v, ",") {
+			if subsystem = strings.TrimSpace(subsystem); subsystem != "" {
+				s.Subsystems = append(s.Subsystems, subsystem)
+			}
+		}
+		if err := validateSubsystems(s.Subsystems); err != nil {
+			return nil, fmt.Errorf("'exporter_subsystems' atribute value '%s' is invalid: %s", v, err)
+		}
+	}
+	return s, nil
+}
+
+// getEnabledSubsystems returns the sorted list of the subsystems being
+// collected from the node, or all when all of them are enabled.
+func (n *NetworkNode) getEnabledSubsystems() string {
+	if n.enabledSubsystems == nil {
+		return "all"
+	}
+	subsystems := []string{}
+	for k := range n.enabledSubsystems {
+		subsystems = append(subsystems, k)
+	}
+	sort.Strings(subsystems)
+	return strings.Join(subsystems, ",")
+}
+
+// isSubsystemEnabled checks whether the data of a subsystem is being
+// collected from the node. All subsystems are enabled by default.
+func (n *NetworkNode) isSubsystemEnabled(subsystem string) bool {
+	if n.enabledSubsystems == nil {
+		return true
+	}
+	return n.enabledSubsystems[subsystem]
+}
+
 // Collect implements prometheus.Collector.
 func (n *NetworkNode) Collect(ch chan<- prometheus.Metric) {
 	start := time.Now()
@@ -71,33 +176,33 @@ func (n *NetworkNode) Collect(ch chan<- prometheus.Metric) {
 	log.Debugf("%s: Collect() successful RLock()", n.UUID)
 	if len(n.metrics) == 0 {
 		log.Debugf("%s: Collect() no metrics found", n.UUID)
-		ch <- prometheus.MustNewConstMetric(
+		ch <- n.newConstMetric(
 			nodeUp,
 			prometheus.GaugeValue,
 			0,
 			n.UUID,
 		)
-		ch <- prometheus.MustNewConstMetric(
+		ch <- n.newConstMetric(
 			nodeHostname,
 			prometheus.GaugeValue,
 			1,
 			n.UUID,
 			n.Name,
 		)
-		ch <- prometheus.MustNewConstMetric(
+		ch <- n.newConstMetric(
 			nodeErrors,
 			prometheus.CounterValue,
 			float64(n.errors),
 			n.UUID,
 		)
-		ch <- prometheus.MustNewConstMetric(
+		ch <- n.newConstMetric(
 			nodeNextScrape,
 			prometheus.CounterValue,
 			float64(n.nextCollectionTicker),
 			n.UUID,
 		)
 
-		ch <- prometheus.MustNewConstMetric(
+		ch <- n.newConstMetric(
 			nodeScrapeTime,
 			prometheus.GaugeValue,
 			time.Since(start).Seconds(),
@@ -0,0 +1,127 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"time"
+)
+
+const (
+	// maxPollHistory is the number of the last polls of a node kept
+	// for the node detail page.
+	maxPollHistory = 10
+)
+
+// subsystemStatus is the outcome of the last collection of a subsystem.
+type subsystemStatus struct {
+	Subsystem string
+	Result    string
+	Error     string
+	Duration  time.Duration
+	Series    int
+	Timestamp time.Time
+}
+
+// credentialAttempt is the outcome of an attempt to authenticate to
+// a node with one of its credentials.
+type credentialAttempt struct {
+	Description string
+	Username    string
+	Result      string
+	Error       string
+}
+
+// pollOutcome is the outcome of a poll of a node.
+type pollOutcome struct {
+	Timestamp time.Time
+	Result    string
+	Duration  time.Duration
+	Error     string
+}
+
+// getSubsystemStatus returns the status of a subsystem. The caller
+// must hold statusLocker.
+func (n *NetworkNode) getSubsystemStatus(subsystem string) *subsystemStatus {
+	if n.statuses == nil {
+		n.statuses = make(map[string]*subsystemStatus)
+	}
+	st, exists := n.statuses[subsystem]
+	if !exists {
+		st = &subsystemStatus{Subsystem: subsystem}
+		n.statuses[subsystem] = st
+	}
+	return st
+}
+
+// setLastError records the outcome of the last collection of
+// a subsystem. A nil error clears the previously recorded one.
+func (n *NetworkNode) setLastError(subsystem string, err error) {
+	n.statusLocker.Lock()
+	defer n.statusLocker.Unlock()
+	st := n.getSubsystemStatus(subsystem)
+	st.Timestamp = time.Now()
+	if err == nil {
+		st.Result = "success"
+		st.Error = ""
+		return
+	}
+	st.Result = "failure"
+	st.Error = err.Error()
+}
+
+// setLastDuration records the amount of time the last collection of
+// a subsystem took.
+func (n *NetworkNode) setLastDuration(subsystem string, d time.Duration) {
+	n.statusLocker.Lock()
+	defer n.statusLocker.Unlock()
+	n.getSubsystemStatus(subsystem).Duration = d
+}
+
+// setSeriesCount records the number of the metrics collected by each
+// subsystem during the last poll.
+func (n *NetworkNode) setSeriesCount(counts map[string]int) {
+	n.statusLocker.Lock()
+	defer n.statusLocker.Unlock()
+	for _, st := range n.statuses {
+		st.Series = 0
+	}
+	for subsystem, count := range counts {
+		n.getSubsystemStatus(subsystem).Series = count
+	}
+}
+
+// getLastErrors returns the last error messages of the subsystems.
+// The message is empty when the last collection succeeded.
+func (n *NetworkNode) getLastErrors() map[string]string {
+	n.statusLocker.RLock()
+	defer n.statusLocker.RUnlock()
+	m := make(map[string]string)
+	for k, st := range n.statuses {
+		m[k] = st.Error
+	}
+	return m
+}
+
+// addPollOutcome records the outcome of a poll and the credential
+// attempts made during the poll.
+func (n *NetworkNode) addPollOutcome(p *pollOutcome, attempts []*credentialAttempt) {
+	n.statusLocker.Lock()
+	defer n.statusLocker.Unlock()
+	n.credentialAttempts = attempts
+	n.pollHistory = append(n.pollHistory, p)
+	if len(n.pollHistory) > maxPollHistory {
+		n.pollHistory = n.pollHistory[len(n.pollHistory)-maxPollHistory:]
+	}
+}
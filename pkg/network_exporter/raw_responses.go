@@ -0,0 +1,225 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RawResponsesPath is the path of the raw device response debug endpoint.
+	RawResponsesPath = "/debug/raw"
+)
+
+// rawResponse is a response received from a network node, as it came
+// over the wire, with credentials stripped.
+type rawResponse struct {
+	node      string
+	subsystem string
+	command   string
+	status    int
+	timestamp time.Time
+	data      []byte
+	json      bool
+}
+
+type rawResponseKey struct {
+	node      string
+	subsystem string
+}
+
+// rawResponseStore holds the last responses received from network nodes,
+// up to size responses per node and subsystem. When the limit is reached,
+// the oldest response is dropped.
+type rawResponseStore struct {
+	sync.RWMutex
+	size    int
+	entries map[rawResponseKey][]*rawResponse
+}
+
+func newRawResponseStore(size int) *rawResponseStore {
+	if size < 1 {
+		return nil
+	}
+	return &rawResponseStore{
+		size:    size,
+		entries: make(map[rawResponseKey][]*rawResponse),
+	}
+}
+
+// add stores a response body in the store. The values of the fields
+// whose names look like credentials, and any occurrence of the secrets,
+// are replaced with REDACTED.
+func (s *rawResponseStore) add(node, subsystem, command string, status int, body []byte, secrets []string) {
+	if s == nil {
+		return
+	}
+	data, isJSON := getRedactedBody(body, secrets)
+	resp := &rawResponse{
+		node:      node,
+		subsystem: subsystem,
+		command:   command,
+		status:    status,
+		timestamp: time.Now(),
+		data:      data,
+		json:      isJSON,
+	}
+	key := rawResponseKey{node, subsystem}
+	s.Lock()
+	defer s.Unlock()
+	entries := append([]*rawResponse{resp}, s.entries[key]...)
+	if len(entries) > s.size {
+		entries = entries[:s.size]
+	}
+	s.entries[key] = entries
+}
+
+// get returns the responses received from a node for a subsystem,
+// the latest first.
+func (s *rawResponseStore) get(node, subsystem string) []*rawResponse {
+	if s == nil {
+		return nil
+	}
+	s.RLock()
+	defer s.RUnlock()
+	return s.entries[rawResponseKey{node, subsystem}]
+}
+
+// removeNode drops the responses of a node removed from the inventory.
+func (s *rawResponseStore) removeNode(node string) {
+	if s == nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	for key := range s.entries {
+		if key.node == node {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// getRedactedBody returns a response body with credentials stripped.
+// A JSON body is indented, and its numbers are kept verbatim.
+func getRedactedBody(body []byte, secrets []string) ([]byte, bool) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err == nil && !dec.More() {
+		if data, err := json.MarshalIndent(redactJSON("", doc, secrets), "", "  "); err == nil {
+			return data, true
+		}
+	}
+	s := string(body)
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.Replace(s, secret, "REDACTED", -1)
+		}
+	}
+	return []byte(s), false
+}
+
+func redactJSON(k string, v interface{}, secrets []string) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, v := range x {
+			x[k] = redactJSON(k, v, secrets)
+		}
+		return x
+	case []interface{}:
+		for i, v := range x {
+			x[i] = redactJSON("", v, secrets)
+		}
+		return x
+	case string:
+		if k != "" && redactVariable(k, x) != x {
+			return "REDACTED"
+		}
+		for _, secret := range secrets {
+			if secret != "" {
+				x = strings.Replace(x, secret, "REDACTED", -1)
+			}
+		}
+		return x
+	}
+	return v
+}
+
+// getSecrets returns the passwords of a node, which must never appear
+// in the raw device responses.
+func (n *NetworkNode) getSecrets() []string {
+	secrets := []string{}
+	for _, c := range n.credentials {
+		secrets = append(secrets, c.Password)
+	}
+	return secrets
+}
+
+// RawResponse serves the last response the collector of a subsystem
+// received from a network node, e.g. /debug/raw?node=ny-sw01&subsystem=interfaces.
+// The index parameter selects an earlier response, 0 being the last one.
+func (e *Exporter) RawResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache, max-age=0, must-revalidate, no-store")
+	_, scope, authorized := e.authorize(r)
+	if !authorized || !scope.admin {
+		writeJSONError(w, http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+	nodeName := r.URL.Query().Get("node")
+	subsystem := r.URL.Query().Get("subsystem")
+	if nodeName == "" || subsystem == "" {
+		writeJSONError(w, http.StatusBadRequest, "both node and subsystem parameters are required")
+		return
+	}
+	n, exists := e.getNode(nodeName)
+	if !exists || !scope.allowsNode(n) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("unknown node %q", nodeName))
+		return
+	}
+	if e.rawResponses == nil {
+		writeJSONError(w, http.StatusNotFound, "raw device responses are not being kept")
+		return
+	}
+	index := 0
+	if v := r.URL.Query().Get("index"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid index %q", v))
+			return
+		}
+		index = i
+	}
+	responses := e.rawResponses.get(n.Name, subsystem)
+	if index >= len(responses) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("no response for %s subsystem of %s node", subsystem, nodeName))
+		return
+	}
+	resp := responses[index]
+	if resp.json {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Header().Set("Last-Modified", resp.timestamp.UTC().Format(http.TimeFormat))
+	w.Write(resp.data)
+	w.Write([]byte("\n"))
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRawResponseStore(t *testing.T) {
+	s := newRawResponseStore(2)
+	body := []byte(`{"host_name": "ny-sw01", "snmp_community": "public", "banner": "login with cisco123", "octets": 18446744073709551615}`)
+	s.add("ny-sw01", "system", "show version", 200, body, []string{"cisco123"})
+	responses := s.get("ny-sw01", "system")
+	if len(responses) != 1 {
+		t.Fatalf("expected 1 response, but got %d", len(responses))
+	}
+	data := string(responses[0].data)
+	if !responses[0].json {
+		t.Errorf("expected the response to be JSON")
+	}
+	if !strings.Contains(data, `"host_name": "ny-sw01"`) || !strings.Contains(data, `"octets": 18446744073709551615`) {
+		t.Errorf("expected host_name and octets to be kept verbatim, but got %s", data)
+	}
+	if strings.Contains(data, "public") || strings.Contains(data, "cisco123") {
+		t.Errorf("expected credentials to be stripped, but got %s", data)
+	}
+
+	s.add("ny-sw01", "system", "show version", 401, []byte("<html>401 Unauthorized for cisco123</html>"), []string{"cisco123"})
+	s.add("ny-sw01", "system", "show version", 200, []byte(`{"host_name": "ny-sw01"}`), nil)
+	s.add("ny-sw02", "vlans", "show vlan", 200, []byte(`{}`), nil)
+	responses = s.get("ny-sw01", "system")
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses, but got %d", len(responses))
+	}
+	if responses[0].status != 200 || responses[1].status != 401 {
+		t.Errorf("expected the latest responses first, but got %d and %d", responses[0].status, responses[1].status)
+	}
+	if responses[1].json || string(responses[1].data) != "<html>401 Unauthorized for REDACTED</html>" {
+		t.Errorf("unexpected non-JSON response: %s", responses[1].data)
+	}
+	if len(s.get("ny-sw02", "vlans")) != 1 {
+		t.Errorf("expected the responses of other nodes to be kept")
+	}
+	s.removeNode("ny-sw01")
+	if len(s.get("ny-sw01", "system")) != 0 || len(s.get("ny-sw02", "vlans")) != 1 {
+		t.Errorf("expected only the responses of the removed node to be dropped")
+	}
+	if newRawResponseStore(0) != nil {
+		t.Errorf("expected no store when the size is zero")
+	}
+}
+
+func TestDeviceTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"cmd": "show vlan"`) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"result": {"body": {"TABLE_vlanbrief": {"ROW_vlanbrief": []}}}, "password": "cisco"}`))
+	}))
+	defer ts.Close()
+	n := &NetworkNode{
+		Name:         "ny-sw01",
+		credentials:  []*credential{{Username: "admin", Password: "cisco"}},
+		rawResponses: newRawResponseStore(5),
+	}
+	transport, err := n.getDeviceTransport()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	client := &http.Client{Transport: transport}
+	req := `[{"jsonrpc": "2.0", "method": "cli", "params": {"cmd": "show vlan", "version": 1}, "id": 1}]`
+	resp, err := client.Post(ts.URL+"/ins", "application/json-rpc", strings.NewReader(req))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"password": "cisco"`) {
+		t.Fatalf("expected the client to get the response intact, but got %d %s", resp.StatusCode, body)
+	}
+	responses := n.rawResponses.get("ny-sw01", "vlans")
+	if len(responses) != 1 {
+		t.Fatalf("expected 1 raw response, but got %d", len(responses))
+	}
+	if responses[0].command != "show vlan" || !strings.Contains(string(responses[0].data), `"TABLE_vlanbrief"`) {
+		t.Errorf("unexpected raw response: %s %s", responses[0].command, responses[0].data)
+	}
+	if strings.Contains(string(responses[0].data), "cisco") {
+		t.Errorf("expected credentials to be stripped, but got %s", responses[0].data)
+	}
+}
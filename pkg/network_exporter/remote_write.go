@@ -0,0 +1,362 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/log"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RemoteWriteOptions are the settings of the push mode. In the push mode,
+// the exporter polls its nodes on a schedule and sends the metrics to a
+// Prometheus remote write endpoint.
+type RemoteWriteOptions struct {
+	// The URL of the remote write endpoint, e.g.
+	// http://prometheus:9090/api/v1/write.
+	URL string
+	// The interval between the polls of the nodes.
+	Interval time.Duration
+	// The timeout of a remote write request.
+	Timeout time.Duration
+	// The maximum number of samples in a remote write request.
+	BatchSize int
+	// The maximum number of samples kept for retries when the endpoint
+	// is unavailable. The oldest samples are dropped first.
+	BufferSize int
+	// The value of job label. The value of instance label is the name of
+	// the node.
+	Job string
+	// The labels added to every series, unless the series has them.
+	ExternalLabels map[string]string
+	// The credentials for the endpoint: either basic auth or a bearer
+	// token.
+	Username    string
+	Password    string
+	BearerToken string
+}
+
+// The following types are the subset of the remote write protocol
+// messages, see prometheus/prompb/remote.proto and types.proto.
+
+type prompbWriteRequest struct {
+	Timeseries []*prompbTimeSeries `protobuf:"bytes,1,rep,name=timeseries,proto3" json:"timeseries"`
+}
+
+func (m *prompbWriteRequest) Reset()         { *m = prompbWriteRequest{} }
+func (m *prompbWriteRequest) String() string { return proto.CompactTextString(m) }
+func (*prompbWriteRequest) ProtoMessage()    {}
+
+type prompbTimeSeries struct {
+	Labels  []*prompbLabel  `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels"`
+	Samples []*prompbSample `protobuf:"bytes,2,rep,name=samples,proto3" json:"samples"`
+}
+
+func (m *prompbTimeSeries) Reset()         { *m = prompbTimeSeries{} }
+func (m *prompbTimeSeries) String() string { return proto.CompactTextString(m) }
+func (*prompbTimeSeries) ProtoMessage()    {}
+
+type prompbLabel struct {
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value"`
+}
+
+func (m *prompbLabel) Reset()         { *m = prompbLabel{} }
+func (m *prompbLabel) String() string { return proto.CompactTextString(m) }
+func (*prompbLabel) ProtoMessage()    {}
+
+type prompbSample struct {
+	Value     float64 `protobuf:"fixed64,1,opt,name=value,proto3" json:"value"`
+	Timestamp int64   `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp"`
+}
+
+func (m *prompbSample) Reset()         { *m = prompbSample{} }
+func (m *prompbSample) String() string { return proto.CompactTextString(m) }
+func (*prompbSample) ProtoMessage()    {}
+
+// remoteWriter sends the metrics of the nodes to a remote write endpoint.
+type remoteWriter struct {
+	opts    RemoteWriteOptions
+	client  *http.Client
+	pending []*prompbTimeSeries
+	locker  sync.Mutex
+}
+
+// remoteWriteError is the error of a remote write request. The requests
+// failed due to network errors, 5xx, or 429 responses are retried.
+type remoteWriteError struct {
+	err         error
+	recoverable bool
+}
+
+func (e *remoteWriteError) Error() string {
+	return e.err.Error()
+}
+
+func newRemoteWriter(opts RemoteWriteOptions) (*remoteWriter, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid remote write url %q", opts.URL)
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 60 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100000
+	}
+	if opts.BufferSize < opts.BatchSize {
+		opts.BufferSize = opts.BatchSize
+	}
+	if opts.Job == "" {
+		opts.Job = appName
+	}
+	for k := range opts.ExternalLabels {
+		if !isValidLabelName(k) {
+			return nil, fmt.Errorf("invalid external label name %q", k)
+		}
+	}
+	w := &remoteWriter{
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+	}
+	return w, nil
+}
+
+// isValidLabelName checks whether a label name matches
+// [a-zA-Z_][a-zA-Z0-9_]*.
+func isValidLabelName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// StartRemoteWrite starts the push mode.
+func (e *Exporter) StartRemoteWrite(opts RemoteWriteOptions) error {
+	w, err := newRemoteWriter(opts)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for {
+			w.push(e.getNodes())
+			time.Sleep(w.opts.Interval)
+		}
+	}()
+	return nil
+}
+
+// push polls the nodes and sends their metrics along with the metrics
+// pending from the previous pushes.
+func (w *remoteWriter) push(nodes []*NetworkNode) {
+	now := time.Now()
+	for _, n := range nodes {
+		registry := prometheus.NewRegistry()
+		if err := registry.Register(n); err != nil {
+			log.Errorf("remote write failed to register node %s: %s", n.Name, err)
+			continue
+		}
+		families, err := registry.Gather()
+		if err != nil {
+			log.Errorf("remote write failed to gather metrics of node %s: %s", n.Name, err)
+		}
+		w.add(w.getTimeSeries(n.Name, families, now))
+	}
+	if err := w.flush(); err != nil {
+		log.Errorf("remote write failed: %s", err)
+	}
+}
+
+// add buffers the series. When the buffer is full, the oldest series are
+// dropped.
+func (w *remoteWriter) add(series []*prompbTimeSeries) {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	w.pending = append(w.pending, series...)
+	if overflow := len(w.pending) - w.opts.BufferSize; overflow > 0 {
+		exporterRemoteWriteSamples.WithLabelValues("dropped").Add(float64(overflow))
+		w.pending = w.pending[overflow:]
+	}
+	exporterRemoteWritePending.Set(float64(len(w.pending)))
+}
+
+// flush sends the pending series in batches. It stops at the first
+// recoverable error, and the remaining series are retried on the next
+// push. The batches rejected by the endpoint are dropped.
+func (w *remoteWriter) flush() error {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	defer func() {
+		exporterRemoteWritePending.Set(float64(len(w.pending)))
+	}()
+	var lastErr error
+	for len(w.pending) > 0 {
+		size := w.opts.BatchSize
+		if size > len(w.pending) {
+			size = len(w.pending)
+		}
+		if err := w.send(w.pending[:size]); err != nil {
+			exporterRemoteWriteFailedRequests.Inc()
+			if e, ok := err.(*remoteWriteError); ok && e.recoverable {
+				return fmt.Errorf("%s, %d samples pending", err, len(w.pending))
+			}
+			exporterRemoteWriteSamples.WithLabelValues("dropped").Add(float64(size))
+			lastErr = err
+		} else {
+			exporterRemoteWriteSamples.WithLabelValues("sent").Add(float64(size))
+		}
+		w.pending = w.pending[size:]
+	}
+	return lastErr
+}
+
+// send sends a remote write request.
+func (w *remoteWriter) send(series []*prompbTimeSeries) error {
+	data, err := proto.Marshal(&prompbWriteRequest{Timeseries: series})
+	if err != nil {
+		return &remoteWriteError{err: fmt.Errorf("error encoding remote write request: %s", err)}
+	}
+	req, err := http.NewRequest("POST", w.opts.URL, bytes.NewReader(snappy.Encode(nil, data)))
+	if err != nil {
+		return &remoteWriteError{err: fmt.Errorf("error creating remote write request: %s", err)}
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", appName+"/"+appVersion)
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.opts.Username != "" {
+		req.SetBasicAuth(w.opts.Username, w.opts.Password)
+	} else if w.opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+w.opts.BearerToken)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return &remoteWriteError{err: fmt.Errorf("error sending remote write request: %s", err), recoverable: true}
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	err = fmt.Errorf("remote write endpoint responded with %s: %s", resp.Status, bytes.TrimSpace(body))
+	recoverable := resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests
+	return &remoteWriteError{err: err, recoverable: recoverable}
+}
+
+// getTimeSeries converts the metric families of a node to time series.
+// The histograms and summaries become _bucket, _sum, and _count series.
+func (w *remoteWriter) getTimeSeries(instance string, families []*dto.MetricFamily, now time.Time) []*prompbTimeSeries {
+	series := []*prompbTimeSeries{}
+	ts := now.UnixNano() / int64(time.Millisecond)
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			timestamp := ts
+			if m.TimestampMs != nil {
+				timestamp = m.GetTimestampMs()
+			}
+			add := func(name string, value float64, extra ...*dto.LabelPair) {
+				labels := append(append([]*dto.LabelPair{}, m.GetLabel()...), extra...)
+				series = append(series, &prompbTimeSeries{
+					Labels:  w.getLabels(name, instance, labels),
+					Samples: []*prompbSample{{Value: value, Timestamp: timestamp}},
+				})
+			}
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add(mf.GetName(), m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(mf.GetName(), m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(mf.GetName(), m.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				for _, q := range m.GetSummary().GetQuantile() {
+					add(mf.GetName(), q.GetValue(), newLabelPair("quantile", formatFloat(q.GetQuantile())))
+				}
+				add(mf.GetName()+"_sum", m.GetSummary().GetSampleSum())
+				add(mf.GetName()+"_count", float64(m.GetSummary().GetSampleCount()))
+			case dto.MetricType_HISTOGRAM:
+				for _, b := range m.GetHistogram().GetBucket() {
+					add(mf.GetName()+"_bucket", float64(b.GetCumulativeCount()), newLabelPair("le", formatFloat(b.GetUpperBound())))
+				}
+				add(mf.GetName()+"_bucket", float64(m.GetHistogram().GetSampleCount()), newLabelPair("le", "+Inf"))
+				add(mf.GetName()+"_sum", m.GetHistogram().GetSampleSum())
+				add(mf.GetName()+"_count", float64(m.GetHistogram().GetSampleCount()))
+			}
+		}
+	}
+	return series
+}
+
+// getLabels returns the sorted labels of a series: the metric name, the
+// labels of the metric, job and instance labels, and the external labels.
+func (w *remoteWriter) getLabels(name, instance string, pairs []*dto.LabelPair) []*prompbLabel {
+	labels := map[string]string{
+		"__name__": name,
+		"job":      w.opts.Job,
+		"instance": instance,
+	}
+	for _, p := range pairs {
+		labels[p.GetName()] = p.GetValue()
+	}
+	for k, v := range w.opts.ExternalLabels {
+		if _, exists := labels[k]; !exists {
+			labels[k] = v
+		}
+	}
+	names := []string{}
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	result := []*prompbLabel{}
+	for _, k := range names {
+		result = append(result, &prompbLabel{Name: k, Value: labels[k]})
+	}
+	return result
+}
+
+func newLabelPair(name, value string) *dto.LabelPair {
+	return &dto.LabelPair{Name: proto.String(name), Value: proto.String(value)}
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,140 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	simulator "github.com/greenpau/network_exporter/pkg/nxapi_sim"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// remoteWriteReceiver is the stand-in for a remote write endpoint.
+type remoteWriteReceiver struct {
+	sync.Mutex
+	status   int
+	requests int
+	series   []*prompbTimeSeries
+}
+
+func (rw *remoteWriteReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rw.Lock()
+	defer rw.Unlock()
+	rw.requests++
+	if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+		http.Error(w, "unsupported encoding", http.StatusBadRequest)
+		return
+	}
+	if rw.status != http.StatusOK {
+		http.Error(w, http.StatusText(rw.status), rw.status)
+		return
+	}
+	compressed, _ := ioutil.ReadAll(r.Body)
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &prompbWriteRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rw.series = append(rw.series, req.Timeseries...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rw *remoteWriteReceiver) findSeries(name string) map[string]string {
+	rw.Lock()
+	defer rw.Unlock()
+	for _, s := range rw.series {
+		labels := make(map[string]string)
+		for _, l := range s.Labels {
+			labels[l.Name] = l.Value
+		}
+		if labels["__name__"] == name {
+			return labels
+		}
+	}
+	return nil
+}
+
+func TestRemoteWrite(t *testing.T) {
+	ts, err := simulator.NewTestServer(simulator.Options{Hostname: "ny-sw01"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer ts.Close()
+	e := newSimulatedExporter(t, ts)
+
+	receiver := &remoteWriteReceiver{status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	w, err := newRemoteWriter(RemoteWriteOptions{
+		URL:            server.URL + "/api/v1/write",
+		BatchSize:      10,
+		ExternalLabels: map[string]string{"site": "ny4", "node": "ignored"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the endpoint is unavailable, and the samples are kept for retries
+	w.push(e.getNodes())
+	pending := len(w.pending)
+	if pending == 0 || receiver.requests != 1 {
+		t.Fatalf("expected pending samples after a single failed request, but got %d samples and %d requests", pending, receiver.requests)
+	}
+
+	receiver.status = http.StatusOK
+	if err := w.flush(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(w.pending) != 0 || len(receiver.series) != pending {
+		t.Errorf("expected %d series to be sent, but got %d, and %d pending", pending, len(receiver.series), len(w.pending))
+	}
+	if expected := 1 + (pending+9)/10; receiver.requests != expected {
+		t.Errorf("expected %d requests, but got %d", expected, receiver.requests)
+	}
+	labels := receiver.findSeries("net_node_up")
+	if labels == nil {
+		t.Fatalf("expected net_node_up series")
+	}
+	for k, v := range map[string]string{"node": "ny-sw01", "site": "ny4", "job": "network-exporter", "instance": "ny-sw01"} {
+		if labels[k] != v {
+			t.Errorf("expected label %s=%q, but got %q", k, v, labels[k])
+		}
+	}
+
+	// the samples rejected by the endpoint are dropped
+	receiver.status = http.StatusBadRequest
+	w.add([]*prompbTimeSeries{{Labels: []*prompbLabel{{Name: "__name__", Value: "net_node_up"}}}})
+	if err := w.flush(); err == nil || len(w.pending) != 0 {
+		t.Errorf("expected rejected samples to be dropped")
+	}
+
+	// the buffer keeps the newest samples
+	w.opts.BufferSize = 10
+	receiver.status = http.StatusServiceUnavailable
+	w.push(e.getNodes())
+	if len(w.pending) != 10 {
+		t.Errorf("expected the buffer to be capped at 10 samples, but got %d", len(w.pending))
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"strings"
+)
+
+// RuleThresholds are the tunable settings of the Prometheus alerting and
+// recording rules produced by the exporter.
+type RuleThresholds struct {
+	// The window of the rates of interface counters, e.g. 5m.
+	RateWindow string `yaml:"rate_window"`
+	// The duration of the conditions before the alerts fire.
+	For string `yaml:"for"`
+	// The duration of node outages before the alert fires.
+	NodeDownFor string `yaml:"node_down_for"`
+	// The rates, per second, of interface errors and discards.
+	IfaceErrorsRate   float64 `yaml:"iface_errors_rate"`
+	IfaceDiscardsRate float64 `yaml:"iface_discards_rate"`
+	// The receive power, in dBm, of optics near their alarm thresholds.
+	// The lanes below the floor are dark and do not trigger the alert.
+	OpticRxPowerLow   float64 `yaml:"optic_rx_power_low"`
+	OpticRxPowerHigh  float64 `yaml:"optic_rx_power_high"`
+	OpticRxPowerFloor float64 `yaml:"optic_rx_power_floor"`
+	// The margin, in degrees, between the temperature of a sensor and
+	// its alarm upper threshold.
+	SensorTemperatureMargin float64 `yaml:"sensor_temperature_margin"`
+	// The CPU and memory utilization, in percent.
+	CPUUtilization    float64 `yaml:"cpu_utilization"`
+	MemoryUtilization float64 `yaml:"memory_utilization"`
+	// The Prometheus job scraping the metrics of the exporter itself at
+	// /exporter/metrics. The alerts on the exporter metrics are produced
+	// only when the job is set.
+	ExporterMetricsJob string `yaml:"exporter_metrics_job"`
+}
+
+// NewRuleThresholds returns the default rule thresholds.
+func NewRuleThresholds() *RuleThresholds {
+	return &RuleThresholds{
+		RateWindow:              "5m",
+		For:                     "10m",
+		NodeDownFor:             "5m",
+		IfaceErrorsRate:         1,
+		IfaceDiscardsRate:       10,
+		OpticRxPowerLow:         -14,
+		OpticRxPowerHigh:        2,
+		OpticRxPowerFloor:       -30,
+		SensorTemperatureMargin: 5,
+		CPUUtilization:          90,
+		MemoryUtilization:       90,
+	}
+}
+
+// LoadRuleThresholds reads the rule thresholds from a YAML file. The
+// thresholds missing from the file keep their default values.
+func LoadRuleThresholds(fp string) (*RuleThresholds, error) {
+	t := NewRuleThresholds()
+	if fp == "" {
+		return t, nil
+	}
+	data, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rule thresholds file: %s", err)
+	}
+	if err := yaml.UnmarshalStrict(data, t); err != nil {
+		return nil, fmt.Errorf("error parsing rule thresholds file %s: %s", fp, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rule thresholds file %s: %s", fp, err)
+	}
+	return t, nil
+}
+
+// Validate checks the rule thresholds for errors.
+func (t *RuleThresholds) Validate() error {
+	for k, v := range map[string]string{
+		"rate_window":   t.RateWindow,
+		"for":           t.For,
+		"node_down_for": t.NodeDownFor,
+	} {
+		if _, err := model.ParseDuration(v); err != nil {
+			return fmt.Errorf("%s: %s", k, err)
+		}
+	}
+	if t.OpticRxPowerFloor >= t.OpticRxPowerLow {
+		return fmt.Errorf("optic_rx_power_floor must be below optic_rx_power_low")
+	}
+	if t.OpticRxPowerLow >= t.OpticRxPowerHigh {
+		return fmt.Errorf("optic_rx_power_low must be below optic_rx_power_high")
+	}
+	for k, v := range map[string]float64{
+		"cpu_utilization":    t.CPUUtilization,
+		"memory_utilization": t.MemoryUtilization,
+	} {
+		if v <= 0 || v > 100 {
+			return fmt.Errorf("%s must be between 0 and 100", k)
+		}
+	}
+	return nil
+}
+
+type ruleFile struct {
+	Groups []*ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string  `yaml:"name"`
+	Rules []*rule `yaml:"rules"`
+}
+
+type rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// metricName returns the name of the metric of a descriptor.
+func metricName(desc *prometheus.Desc) string {
+	return descInfos[desc].fqName
+}
+
+// GetRules returns the Prometheus rule file with the recording and
+// alerting rules for the metrics produced by the exporter.
+func GetRules(t *RuleThresholds) (string, error) {
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+	w := t.RateWindow
+	rate := func(descs ...*prometheus.Desc) string {
+		exprs := []string{}
+		for _, desc := range descs {
+			exprs = append(exprs, fmt.Sprintf("rate(%s[%s])", metricName(desc), w))
+		}
+		return fmt.Sprintf("sum by (node, iface) (%s)", strings.Join(exprs, " + "))
+	}
+	ifaceErrors := fmt.Sprintf("node_iface:%s_%s:rate%s", namespace, "iface_errors", w)
+	ifaceDiscards := fmt.Sprintf("node_iface:%s_%s:rate%s", namespace, "iface_discards", w)
+	cpuUtilization := fmt.Sprintf("node:%s_%s:percent", namespace, "node_cpu_utilization")
+	memoryUtilization := fmt.Sprintf("node:%s_%s:percent", namespace, "node_memory_utilization")
+
+	recording := &ruleGroup{
+		Name: "network-exporter.rules",
+		Rules: []*rule{
+			{
+				Record: ifaceErrors,
+				Expr:   rate(ifaceCounterInputErrors, ifaceCounterOutputErrors),
+			},
+			{
+				Record: ifaceDiscards,
+				Expr:   rate(ifaceCounterInputDiscards, ifaceCounterOutputDiscards),
+			},
+			{
+				Record: cpuUtilization,
+				Expr:   fmt.Sprintf("100 - %s", metricName(cpuUsageTotalIdle)),
+			},
+			{
+				Record: memoryUtilization,
+				Expr: fmt.Sprintf("100 * %s / (%s > 0)",
+					metricName(memoryUsageUsed), metricName(memoryUsageTotal)),
+			},
+		},
+	}
+
+	alert := func(name, severity, expr, duration, summary string) *rule {
+		return &rule{
+			Alert:  name,
+			Expr:   expr,
+			For:    duration,
+			Labels: map[string]string{"severity": severity},
+			Annotations: map[string]string{
+				"summary": summary,
+			},
+		}
+	}
+	alerting := &ruleGroup{
+		Name: "network-exporter.alerts",
+		Rules: []*rule{
+			alert("NetworkNodeDown", "critical",
+				fmt.Sprintf("%s == 0", metricName(nodeUp)),
+				t.NodeDownFor,
+				"Node {{ $labels.node }} is not responding to API requests",
+			),
+			alert("NetworkInterfaceErrors", "warning",
+				fmt.Sprintf("%s > %s", ifaceErrors, formatFloat(t.IfaceErrorsRate)),
+				t.For,
+				"Interface {{ $labels.iface }} of node {{ $labels.node }} has {{ $value }} errors per second",
+			),
+			alert("NetworkInterfaceDiscards", "warning",
+				fmt.Sprintf("%s > %s", ifaceDiscards, formatFloat(t.IfaceDiscardsRate)),
+				t.For,
+				"Interface {{ $labels.iface }} of node {{ $labels.node }} has {{ $value }} discards per second",
+			),
+			alert("NetworkOpticRxPowerLow", "warning",
+				fmt.Sprintf("%s < %s and %s > %s",
+					metricName(transceiverLaneRxPower), formatFloat(t.OpticRxPowerLow),
+					metricName(transceiverLaneRxPower), formatFloat(t.OpticRxPowerFloor)),
+				t.For,
+				"The receive power of lane {{ $labels.lane_id }} of interface {{ $labels.iface_name }} of node {{ $labels.node }} is {{ $value }} dBm",
+			),
+			alert("NetworkOpticRxPowerHigh", "warning",
+				fmt.Sprintf("%s > %s", metricName(transceiverLaneRxPower), formatFloat(t.OpticRxPowerHigh)),
+				t.For,
+				"The receive power of lane {{ $labels.lane_id }} of interface {{ $labels.iface_name }} of node {{ $labels.node }} is {{ $value }} dBm",
+			),
+			alert("NetworkPowerSupplyDown", "critical",
+				fmt.Sprintf("%s == 0", metricName(powerSupplyUp)),
+				t.For,
+				"Power supply {{ $labels.power_supply }} of node {{ $labels.node }} is down",
+			),
+			alert("NetworkFanDown", "critical",
+				fmt.Sprintf("%s == 0", metricName(fanUp)),
+				t.For,
+				"Fan {{ $labels.fan }} of node {{ $labels.node }} is down",
+			),
+			alert("NetworkSensorTemperatureHigh", "warning",
+				fmt.Sprintf("%s > on (node, sensor) (%s - %s) and on (node, sensor) %s > 0",
+					metricName(sensorTemperature), metricName(sensorTemperatureThresholdHigh),
+					formatFloat(t.SensorTemperatureMargin), metricName(sensorTemperatureThresholdHigh)),
+				t.For,
+				"The temperature of sensor {{ $labels.sensor }} of node {{ $labels.node }} is {{ $value }} degrees",
+			),
+			alert("NetworkNodeCPUHigh", "warning",
+				fmt.Sprintf("%s > %s", cpuUtilization, formatFloat(t.CPUUtilization)),
+				t.For,
+				"The CPU utilization of node {{ $labels.node }} is {{ $value }} percent",
+			),
+			alert("NetworkNodeMemoryHigh", "warning",
+				fmt.Sprintf("%s > %s", memoryUtilization, formatFloat(t.MemoryUtilization)),
+				t.For,
+				"The memory utilization of node {{ $labels.node }} is {{ $value }} percent",
+			),
+			alert("NetworkBGPSessionDown", "critical",
+				fmt.Sprintf("%s == 0", metricName(bgpSessionUp)),
+				t.For,
+				"BGP session with {{ $labels.neighbor }} (AS {{ $labels.remote_as }}) in VRF {{ $labels.vrf }} of node {{ $labels.node }} is down",
+			),
+		},
+	}
+	// the exporter metrics are at /exporter/metrics, scraped by a separate
+	// job, and their node label is the name of the node
+	if t.ExporterMetricsJob != "" {
+		alerting.Rules = append(alerting.Rules,
+			alert("NetworkNodeAuthFailures", "critical",
+				fmt.Sprintf("increase(%s_api_errors_total{job=%q,kind=\"auth\"}[%s]) > 0", exporterNamespace, t.ExporterMetricsJob, w),
+				"",
+				"The exporter fails to authenticate to node {{ $labels.node }}",
+			),
+		)
+	}
+
+	data, err := yaml.Marshal(&ruleFile{Groups: []*ruleGroup{recording, alerting}})
+	if err != nil {
+		return "", fmt.Errorf("error rendering rules: %s", err)
+	}
+	return string(data), nil
+}
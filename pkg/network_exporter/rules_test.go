@@ -0,0 +1,69 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"gopkg.in/yaml.v2"
+	"strings"
+	"testing"
+)
+
+func TestGetRules(t *testing.T) {
+	thresholds, err := LoadRuleThresholds("testdata/rules/thresholds.yml")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	out, err := GetRules(thresholds)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rules := &ruleFile{}
+	if err := yaml.UnmarshalStrict([]byte(out), rules); err != nil {
+		t.Fatalf("error parsing rules: %s\n%s", err, out)
+	}
+	exprs := make(map[string]string)
+	for _, group := range rules.Groups {
+		for _, r := range group.Rules {
+			exprs[r.Record+r.Alert] = r.Expr
+		}
+	}
+	for name, expected := range map[string]string{
+		"node_iface:net_iface_errors:rate2m": "rate(net_iface_input_errors[2m]) + rate(net_iface_output_errors[2m])",
+		"NetworkInterfaceErrors":             "node_iface:net_iface_errors:rate2m > 0.5",
+		"NetworkNodeCPUHigh":                 "node:net_node_cpu_utilization:percent > 75",
+		"NetworkNodeMemoryHigh":              "node:net_node_memory_utilization:percent > 90",
+		"NetworkNodeDown":                    "net_node_up == 0",
+		"NetworkOpticRxPowerLow":             "net_interface_transceiver_lane_rx_power < -14",
+		"NetworkBGPSessionDown":              "net_bgp_session_up == 0",
+		"NetworkNodeAuthFailures":            `network_exporter_api_errors_total{job="network-exporter",kind="auth"}[2m]`,
+	} {
+		if !strings.Contains(exprs[name], expected) {
+			t.Errorf("expected %s rule to contain %q, but got %q", name, expected, exprs[name])
+		}
+	}
+
+	out, err = GetRules(NewRuleThresholds())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Contains(out, "NetworkNodeAuthFailures") {
+		t.Errorf("expected no NetworkNodeAuthFailures rule without the exporter metrics job, but got one")
+	}
+
+	thresholds.RateWindow = "5 minutes"
+	if _, err := GetRules(thresholds); err == nil {
+		t.Errorf("expected error with invalid rate window, but got none")
+	}
+}
@@ -0,0 +1,24 @@
+#!/bin/sh
+if [ "$1" != "--list" ]; then
+  echo "usage: $0 --list" >&2
+  exit 1
+fi
+cat <<'JSON'
+{
+  "ny4-cisco": {
+    "hosts": ["ny-sw01", "ny-sw04"],
+    "vars": {"datacenter": "ny4"}
+  },
+  "cisco": {
+    "children": ["ny4-cisco"],
+    "vars": {"os": "cisco_nxos", "api_proto": "http"}
+  },
+  "lab": ["ny-sw04"],
+  "_meta": {
+    "hostvars": {
+      "ny-sw01": {"host_overwrite": "10.1.1.1", "api_port": 8443},
+      "ny-sw04": {"host_overwrite": "10.1.1.4", "lab": true}
+    }
+  }
+}
+JSON
@@ -0,0 +1,154 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/prometheus/common/log"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSOptions are the options for serving the exporter over HTTPS.
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	// The file with the certificates of the authorities issuing client
+	// certificates. When empty, client certificates are not requested.
+	ClientCAFile string
+	// When true, the clients without a valid certificate are rejected
+	// during TLS handshake.
+	RequireClientCert bool
+}
+
+// tlsReloader keeps the server certificate and client CA pool up to date
+// with the files on disk, so that rotated certificates are picked up
+// without a restart.
+type tlsReloader struct {
+	sync.RWMutex
+	opts          TLSOptions
+	cert          *tls.Certificate
+	certModTime   time.Time
+	keyModTime    time.Time
+	clientCAs     *x509.CertPool
+	clientModTime time.Time
+}
+
+// NewTLSConfig returns TLS configuration for the exporter's HTTP listener.
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, fmt.Errorf("both TLS certificate and key files are required")
+	}
+	if opts.RequireClientCert && opts.ClientCAFile == "" {
+		return nil, fmt.Errorf("client certificate verification requires client CA file")
+	}
+	r := &tlsReloader{opts: opts}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	cfg.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		r.RLock()
+		defer r.RUnlock()
+		return r.cert, nil
+	}
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		if err := r.reload(); err != nil {
+			log.Errorf("failed reloading TLS certificates: %s", err)
+		}
+		r.RLock()
+		defer r.RUnlock()
+		c := cfg.Clone()
+		c.GetConfigForClient = nil
+		c.GetCertificate = nil
+		c.Certificates = []tls.Certificate{*r.cert}
+		if r.clientCAs != nil {
+			c.ClientCAs = r.clientCAs
+			if r.opts.RequireClientCert {
+				c.ClientAuth = tls.RequireAndVerifyClientCert
+			} else {
+				c.ClientAuth = tls.VerifyClientCertIfGiven
+			}
+		}
+		return c, nil
+	}
+	return cfg, nil
+}
+
+// reload reads the certificate, key, and client CA files when they
+// changed since the last read. On failure, the previously loaded
+// certificates remain in use.
+func (r *tlsReloader) reload() error {
+	certModTime, err := getFileModTime(r.opts.CertFile)
+	if err != nil {
+		return err
+	}
+	keyModTime, err := getFileModTime(r.opts.KeyFile)
+	if err != nil {
+		return err
+	}
+	var clientModTime time.Time
+	if r.opts.ClientCAFile != "" {
+		clientModTime, err = getFileModTime(r.opts.ClientCAFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	if r.cert == nil || !certModTime.Equal(r.certModTime) || !keyModTime.Equal(r.keyModTime) {
+		cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+		if err != nil {
+			return fmt.Errorf("error loading TLS certificate: %s", err)
+		}
+		if r.cert != nil {
+			log.Infof("Reloaded TLS certificate %s", r.opts.CertFile)
+		}
+		r.cert = &cert
+		r.certModTime = certModTime
+		r.keyModTime = keyModTime
+	}
+	if r.opts.ClientCAFile != "" && (r.clientCAs == nil || !clientModTime.Equal(r.clientModTime)) {
+		data, err := ioutil.ReadFile(r.opts.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("error reading client CA file: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("client CA file %s has no valid certificates", r.opts.ClientCAFile)
+		}
+		if r.clientCAs != nil {
+			log.Infof("Reloaded client CA file %s", r.opts.ClientCAFile)
+		}
+		r.clientCAs = pool
+		r.clientModTime = clientModTime
+	}
+	return nil
+}
+
+func getFileModTime(fp string) (time.Time, error) {
+	fi, err := os.Stat(fp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return fi.ModTime(), nil
+}
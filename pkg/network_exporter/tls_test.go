@@ -0,0 +1,275 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCertificate returns a certificate signed by the parent, or
+// a self-signed CA certificate when the parent is nil.
+func newTestCertificate(t *testing.T, serial int64, subject pkix.Name, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return cert
+}
+
+func writeTestFile(t *testing.T, fp string, data []byte, modTime time.Time) {
+	if err := ioutil.WriteFile(fp, data, 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := os.Chtimes(fp, modTime, modTime); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func newTestTLSClient(ca *testCertificate, certs ...tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:      pool,
+				Certificates: certs,
+			},
+			DisableKeepAlives: true,
+		},
+	}
+}
+
+func TestTLSReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network-exporter")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	ca := newTestCertificate(t, 1, pkix.Name{CommonName: "Test CA"}, nil)
+	opts := TLSOptions{
+		CertFile: filepath.Join(dir, "tls.crt"),
+		KeyFile:  filepath.Join(dir, "tls.key"),
+	}
+	modTime := time.Now().Add(-time.Minute)
+	server1 := newTestCertificate(t, 10, pkix.Name{CommonName: "localhost"}, ca)
+	writeTestFile(t, opts.CertFile, server1.certPEM, modTime)
+	writeTestFile(t, opts.KeyFile, server1.keyPEM, modTime)
+
+	cfg, err := NewTLSConfig(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.TLS = cfg
+	ts.StartTLS()
+	defer ts.Close()
+	client := newTestTLSClient(ca)
+
+	getServerSerial := func() int64 {
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+	if serial := getServerSerial(); serial != 10 {
+		t.Fatalf("expected server certificate serial 10, but got %d", serial)
+	}
+
+	server2 := newTestCertificate(t, 20, pkix.Name{CommonName: "localhost"}, ca)
+	writeTestFile(t, opts.CertFile, server2.certPEM, modTime.Add(time.Second))
+	writeTestFile(t, opts.KeyFile, server2.keyPEM, modTime.Add(time.Second))
+	if serial := getServerSerial(); serial != 20 {
+		t.Errorf("expected reloaded server certificate serial 20, but got %d", serial)
+	}
+
+	// A broken certificate keeps the previously loaded one in use.
+	writeTestFile(t, opts.CertFile, []byte("invalid"), modTime.Add(2*time.Second))
+	if serial := getServerSerial(); serial != 20 {
+		t.Errorf("expected server certificate serial 20 after failed reload, but got %d", serial)
+	}
+}
+
+func TestTLSClientCertificates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network-exporter")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	ca := newTestCertificate(t, 1, pkix.Name{CommonName: "Test CA"}, nil)
+	otherCA := newTestCertificate(t, 2, pkix.Name{CommonName: "Other CA"}, nil)
+	server := newTestCertificate(t, 10, pkix.Name{CommonName: "localhost"}, ca)
+	opts := TLSOptions{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "client-ca.crt"),
+	}
+	modTime := time.Now().Add(-time.Minute)
+	writeTestFile(t, opts.CertFile, server.certPEM, modTime)
+	writeTestFile(t, opts.KeyFile, server.keyPEM, modTime)
+	writeTestFile(t, opts.ClientCAFile, ca.certPEM, modTime)
+
+	fp := filepath.Join(dir, "tokens.yml")
+	data := "---\nsubjects:\n" +
+		"- subject: 'CN=grafana.example.com,O=Example'\n  groups: [ny4-cisco]\n"
+	if err := ioutil.WriteFile(fp, []byte(data), 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	e := &Exporter{
+		Tokens:   map[string]bool{"anonymous": true},
+		Subjects: map[string]bool{"prometheus.example.com": true},
+	}
+	if err := e.LoadAuthenticationTokens(fp); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, scope, authorized := e.authorize(r)
+		if !authorized {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, "%s/%s", token, scope.name)
+	})
+
+	sw01 := &NetworkNode{Name: "ny-sw01", groups: []string{"ny4-cisco", "ny4"}}
+	sw03 := &NetworkNode{Name: "ny-sw03", groups: []string{"ny5-cisco", "ny5"}}
+	prometheusCert := newTestCertificate(t, 11, pkix.Name{CommonName: "prometheus.example.com"}, ca).tlsCertificate(t)
+	grafanaCert := newTestCertificate(t, 12, pkix.Name{CommonName: "grafana.example.com", Organization: []string{"Example"}}, ca).tlsCertificate(t)
+	unknownCert := newTestCertificate(t, 13, pkix.Name{CommonName: "unknown.example.com"}, ca).tlsCertificate(t)
+	untrustedCert := newTestCertificate(t, 14, pkix.Name{CommonName: "prometheus.example.com"}, otherCA).tlsCertificate(t)
+
+	testCases := []struct {
+		required bool
+		certs    []tls.Certificate
+		url      string
+		status   int
+		body     string
+	}{
+		{false, []tls.Certificate{prometheusCert}, "/", http.StatusOK, "/default"},
+		{false, []tls.Certificate{grafanaCert}, "/", http.StatusOK, "/CN=grafana.example.com,O=Example"},
+		{false, []tls.Certificate{unknownCert}, "/", http.StatusUnauthorized, ""},
+		{false, []tls.Certificate{unknownCert}, "/?x-token=anonymous", http.StatusOK, "anonymous/default"},
+		{false, nil, "/?x-token=anonymous", http.StatusOK, "anonymous/default"},
+		// The client does not offer a certificate issued by an unknown CA.
+		{false, []tls.Certificate{untrustedCert}, "/", http.StatusUnauthorized, ""},
+		{true, []tls.Certificate{prometheusCert}, "/", http.StatusOK, "/default"},
+		{true, nil, "/?x-token=anonymous", 0, ""},
+	}
+	for i, tc := range testCases {
+		opts.RequireClientCert = tc.required
+		cfg, err := NewTLSConfig(opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ts := httptest.NewUnstartedServer(handler)
+		ts.TLS = cfg
+		ts.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+		ts.StartTLS()
+		resp, err := newTestTLSClient(ca, tc.certs...).Get(ts.URL + tc.url)
+		ts.Close()
+		if tc.status == 0 {
+			if err == nil {
+				resp.Body.Close()
+				t.Errorf("test %d: expected TLS handshake failure, but got status %d", i, resp.StatusCode)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %s", i, err)
+			continue
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status || string(body) != tc.body {
+			t.Errorf("test %d: expected %d %q, but got %d %q", i, tc.status, tc.body, resp.StatusCode, body)
+		}
+	}
+
+	scope, exists := e.tokenStore.lookupSubject(pkix.Name{CommonName: "grafana.example.com", Organization: []string{"Example"}})
+	if !exists {
+		t.Fatalf("expected grafana subject to have a scope")
+	}
+	if !scope.allowsNode(sw01) || scope.allowsNode(sw03) || scope.summary || scope.admin {
+		t.Errorf("unexpected grafana subject scope: %+v", scope)
+	}
+}
@@ -0,0 +1,504 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// getResponseBody returns the body of the response to a command. The
+// responses are modeled after the output of a Nexus 9396PX switch.
+func getResponseBody(cmd, hostname string) json.RawMessage {
+	var body string
+	switch cmd {
+	case "show version":
+		body = showVersion
+	case "show interface":
+		body = showInterface
+	case "show vlan", "show vlan brief":
+		body = showVlan
+	case "show environment":
+		body = showEnvironment
+	case "show system resources":
+		body = showSystemResources
+	case "show interface transceiver details":
+		body = showInterfaceTransceiverDetails
+	case "show bgp sessions":
+		body = showBgpSessions
+	default:
+		return nil
+	}
+	return json.RawMessage(strings.Replace(body, "{{hostname}}", hostname, -1))
+}
+
+const showVersion = `{
+  "header_str": "Cisco Nexus Operating System (NX-OS) Software",
+  "bios_ver_str": "07.59",
+  "kickstart_ver_str": "7.0(3)I7(4)",
+  "sys_ver_str": "7.0(3)I7(4)",
+  "bios_cmpl_time": "08/26/2016",
+  "kick_file_name": "bootflash:///nxos.7.0.3.I7.4.bin",
+  "kick_cmpl_time": " 6/14/1970 2:00:00",
+  "kick_tmstmp": "06/14/1970 09:49:04",
+  "chassis_id": "Nexus9000 C9396PX Chassis",
+  "cpu_name": "Intel(R) Core(TM) i3- CPU @ 2.50GHz",
+  "memory": 16400084,
+  "mem_type": "kB",
+  "proc_board_id": "SAL1819S6LU",
+  "host_name": "{{hostname}}",
+  "bootflash_size": 51496280,
+  "kern_uptm_days": 12,
+  "kern_uptm_hrs": 4,
+  "kern_uptm_mins": 31,
+  "kern_uptm_secs": 18,
+  "rr_usecs": 297839,
+  "rr_ctime": "Mon Dec  3 10:15:21 2018",
+  "rr_reason": "Reset Requested by CLI command reload",
+  "rr_sys_ver": "7.0(3)I7(4)",
+  "rr_service": null,
+  "manufacturer": "Cisco Systems, Inc."
+}`
+
+const showInterface = `{
+  "TABLE_interface": {
+    "ROW_interface": [
+      {
+        "interface": "mgmt0",
+        "state": "up",
+        "admin_state": "up",
+        "eth_hw_desc": "GigabitEthernet",
+        "eth_hw_addr": "5254.0012.3401",
+        "eth_bia_addr": "5254.0012.3401",
+        "eth_ip_addr": "10.10.20.58",
+        "eth_ip_mask": 24,
+        "eth_ip_prefix": "10.10.20.0",
+        "eth_mtu": "1500",
+        "eth_bw": 1000000,
+        "eth_dly": 10,
+        "eth_reliability": "255",
+        "eth_txload": "1",
+        "eth_rxload": "1",
+        "medium": "broadcast",
+        "eth_duplex": "full",
+        "eth_speed": "1000 Mb/s",
+        "eth_autoneg": "on",
+        "eth_mdix": "off",
+        "eth_ethertype": "0x0000",
+        "vdc_lvl_in_avg_bytes": 1128,
+        "vdc_lvl_in_avg_pkts": 1,
+        "vdc_lvl_out_avg_bytes": 712,
+        "vdc_lvl_out_avg_pkts": 0,
+        "vdc_lvl_in_pkts": 2254711,
+        "vdc_lvl_in_ucast": 1873421,
+        "vdc_lvl_in_mcast": 294118,
+        "vdc_lvl_in_bcast": 87172,
+        "vdc_lvl_in_bytes": 512309771,
+        "vdc_lvl_out_pkts": 1412883,
+        "vdc_lvl_out_ucast": 1401201,
+        "vdc_lvl_out_mcast": 11587,
+        "vdc_lvl_out_bcast": 95,
+        "vdc_lvl_out_bytes": 298766234
+      },
+      {
+        "interface": "Ethernet1/1",
+        "state": "up",
+        "admin_state": "up",
+        "share_state": "Dedicated",
+        "eth_hw_desc": "100/1000/10000 Ethernet",
+        "eth_hw_addr": "88f0.3187.9c21",
+        "eth_bia_addr": "88f0.3187.9c21",
+        "desc": "peer=ny-sw02;port=Eth1/1;circuit=ABC123;role=uplink",
+        "eth_mtu": "9216",
+        "eth_bw": 10000000,
+        "eth_dly": 10,
+        "eth_reliability": "255",
+        "eth_txload": "3",
+        "eth_rxload": "5",
+        "medium": "broadcast",
+        "eth_mode": "trunk",
+        "eth_duplex": "full",
+        "eth_speed": "10 Gb/s",
+        "eth_beacon": "off",
+        "eth_autoneg": "off",
+        "eth_in_flowctrl": "off",
+        "eth_out_flowctrl": "off",
+        "eth_mdix": "off",
+        "eth_ratemode": "dedicated",
+        "eth_swt_monitor": "off",
+        "eth_ethertype": "0x8100",
+        "eth_eee_state": "n/a",
+        "eth_link_flapped": "12week(s) 2day(s)",
+        "eth_clear_counters": "never",
+        "eth_reset_cntr": 2,
+        "eth_load_interval1_rx": 30,
+        "eth_inrate1_bits": 512304880,
+        "eth_inrate1_pkts": 61234,
+        "eth_load_interval1_tx": 30,
+        "eth_outrate1_bits": 301223088,
+        "eth_outrate1_pkts": 40112,
+        "eth_inucast": 88312442101,
+        "eth_inmcast": 1201844,
+        "eth_inbcast": 23012,
+        "eth_inpkts": 88313666957,
+        "eth_inbytes": 91823112345678,
+        "eth_jumbo_inpkts": 1022334,
+        "eth_storm_supp": 0,
+        "eth_runts": 0,
+        "eth_giants": 0,
+        "eth_crc": 4,
+        "eth_nobuf": 0,
+        "eth_inerr": 4,
+        "eth_frame": 0,
+        "eth_overrun": 0,
+        "eth_underrun": 0,
+        "eth_ignored": 0,
+        "eth_watchdog": 0,
+        "eth_bad_eth": 0,
+        "eth_bad_proto": 0,
+        "eth_in_ifdown_drops": 0,
+        "eth_dribble": 0,
+        "eth_indiscard": 12,
+        "eth_inpause": 0,
+        "eth_outucast": 61234112334,
+        "eth_outmcast": 3401223,
+        "eth_outbcast": 10023,
+        "eth_outpkts": 61237523580,
+        "eth_outbytes": 55102233445566,
+        "eth_jumbo_outpkts": 998122,
+        "eth_outerr": 0,
+        "eth_coll": 0,
+        "eth_deferred": 0,
+        "eth_latecoll": 0,
+        "eth_lostcarrier": 0,
+        "eth_nocarrier": 0,
+        "eth_babbles": 0,
+        "eth_outdiscard": 3,
+        "eth_outpause": 0
+      },
+      {
+        "interface": "Ethernet1/2",
+        "state": "down",
+        "state_rsn_desc": "Link not connected",
+        "admin_state": "up",
+        "share_state": "Dedicated",
+        "eth_hw_desc": "100/1000/10000 Ethernet",
+        "eth_hw_addr": "88f0.3187.9c22",
+        "eth_bia_addr": "88f0.3187.9c22",
+        "desc": "peer=ny-srv01;port=eth0;role=access",
+        "eth_mtu": "1500",
+        "eth_bw": 10000000,
+        "eth_dly": 10,
+        "eth_reliability": "255",
+        "eth_txload": "1",
+        "eth_rxload": "1",
+        "medium": "broadcast",
+        "eth_mode": "access",
+        "eth_duplex": "auto",
+        "eth_speed": "auto-speed",
+        "eth_beacon": "off",
+        "eth_autoneg": "on",
+        "eth_mdix": "off",
+        "eth_ethertype": "0x8100",
+        "eth_link_flapped": "never",
+        "eth_clear_counters": "never",
+        "eth_reset_cntr": 0,
+        "eth_inucast": 0,
+        "eth_inmcast": 0,
+        "eth_inbcast": 0,
+        "eth_inpkts": 0,
+        "eth_inbytes": 0,
+        "eth_jumbo_inpkts": 0,
+        "eth_storm_supp": 0,
+        "eth_runts": 0,
+        "eth_giants": 0,
+        "eth_crc": 0,
+        "eth_nobuf": 0,
+        "eth_inerr": 0,
+        "eth_frame": 0,
+        "eth_overrun": 0,
+        "eth_underrun": 0,
+        "eth_ignored": 0,
+        "eth_watchdog": 0,
+        "eth_bad_eth": 0,
+        "eth_bad_proto": 0,
+        "eth_in_ifdown_drops": 0,
+        "eth_dribble": 0,
+        "eth_indiscard": 0,
+        "eth_inpause": 0,
+        "eth_outucast": 0,
+        "eth_outmcast": 0,
+        "eth_outbcast": 0,
+        "eth_outpkts": 0,
+        "eth_outbytes": 0,
+        "eth_jumbo_outpkts": 0,
+        "eth_outerr": 0,
+        "eth_coll": 0,
+        "eth_deferred": 0,
+        "eth_latecoll": 0,
+        "eth_lostcarrier": 0,
+        "eth_nocarrier": 0,
+        "eth_babbles": 0,
+        "eth_outdiscard": 0,
+        "eth_outpause": 0
+      },
+      {
+        "interface": "Vlan10",
+        "svi_line_proto": "up",
+        "svi_admin_state": "up",
+        "svi_hw_desc": "EtherSVI",
+        "svi_mac": "88f0.3187.9c07",
+        "svi_ip_addr": "192.168.10.1",
+        "svi_ip_mask": 24,
+        "svi_mtu": 1500,
+        "svi_bw": 1000000,
+        "svi_delay": 10,
+        "svi_tx_load": 1,
+        "svi_rx_load": 1
+      },
+      {
+        "interface": "loopback0",
+        "state": "up",
+        "admin_state": "up",
+        "eth_hw_desc": "Loopback",
+        "eth_ip_addr": "10.255.255.1",
+        "eth_ip_mask": 32,
+        "eth_mtu": "1500",
+        "eth_bw": 8000000,
+        "eth_dly": 5000,
+        "eth_reliability": "255",
+        "eth_txload": "1",
+        "eth_rxload": "1",
+        "loop_in_pkts": 0,
+        "loop_in_bytes": 0,
+        "loop_out_pkts": 0,
+        "loop_out_bytes": 0
+      }
+    ]
+  }
+}`
+
+const showVlan = `{
+  "TABLE_vlanbrief": {
+    "ROW_vlanbrief": [
+      {
+        "vlanshowbr-vlanid": 1,
+        "vlanshowbr-vlanid-utf": "1",
+        "vlanshowbr-vlanname": "default",
+        "vlanshowbr-vlanstate": "active",
+        "vlanshowbr-shutstate": "noshutdown",
+        "vlanshowplist-ifidx": "Ethernet1/3-48"
+      },
+      {
+        "vlanshowbr-vlanid": 10,
+        "vlanshowbr-vlanid-utf": "10",
+        "vlanshowbr-vlanname": "servers",
+        "vlanshowbr-vlanstate": "active",
+        "vlanshowbr-shutstate": "noshutdown",
+        "vlanshowplist-ifidx": "Ethernet1/1-2"
+      },
+      {
+        "vlanshowbr-vlanid": 99,
+        "vlanshowbr-vlanid-utf": "99",
+        "vlanshowbr-vlanname": "quarantine",
+        "vlanshowbr-vlanstate": "suspend",
+        "vlanshowbr-shutstate": "shutdown"
+      }
+    ]
+  },
+  "TABLE_mtuinfo": {
+    "ROW_mtuinfo": [
+      {"vlanshowinfo-vlanid": 1, "vlanshowinfo-media-type": "enet", "vlanshowinfo-vlanmode": "ce-vlan"},
+      {"vlanshowinfo-vlanid": 10, "vlanshowinfo-media-type": "enet", "vlanshowinfo-vlanmode": "ce-vlan"},
+      {"vlanshowinfo-vlanid": 99, "vlanshowinfo-media-type": "enet", "vlanshowinfo-vlanmode": "ce-vlan"}
+    ]
+  }
+}`
+
+const showEnvironment = `{
+  "fandetails": {
+    "TABLE_faninfo": {
+      "ROW_faninfo": [
+        {"fanname": "Fan1(sys_fan1)", "fanmodel": "N9K-C9300-FAN2", "fanhwver": "--", "fandir": "front-to-back", "fanstatus": "Ok"},
+        {"fanname": "Fan2(sys_fan2)", "fanmodel": "N9K-C9300-FAN2", "fanhwver": "--", "fandir": "front-to-back", "fanstatus": "Ok"},
+        {"fanname": "Fan3(sys_fan3)", "fanmodel": "N9K-C9300-FAN2", "fanhwver": "--", "fandir": "front-to-back", "fanstatus": "Failure"},
+        {"fanname": "Fan_in_PS1", "fanmodel": "--", "fanhwver": "--", "fandir": "front-to-back", "fanstatus": "Ok"}
+      ]
+    },
+    "fan_filter_status": "NotSupported"
+  },
+  "TABLE_tempinfo": {
+    "ROW_tempinfo": [
+      {"tempmod": "1", "sensor": "FRONT", "majthres": "80", "minthres": "70", "curtemp": "31", "alarmstatus": "Normal"},
+      {"tempmod": "1", "sensor": "BACK", "majthres": "70", "minthres": "42", "curtemp": "27", "alarmstatus": "Normal"},
+      {"tempmod": "1", "sensor": "CPU", "majthres": "90", "minthres": "80", "curtemp": "40", "alarmstatus": "Normal"},
+      {"tempmod": "1", "sensor": "TD2", "majthres": "110", "minthres": "90", "curtemp": "46", "alarmstatus": "Normal"}
+    ]
+  },
+  "powersup": {
+    "voltage_level": 12,
+    "TABLE_psinfo": {
+      "ROW_psinfo": [
+        {"psnum": 1, "psmodel": "N9K-PAC-650W-B", "actual_out": "76 W", "actual_input": "88 W", "tot_capa": "650 W", "ps_status": "Ok"},
+        {"psnum": 2, "psmodel": "N9K-PAC-650W-B", "actual_out": "0 W", "actual_input": "0 W", "tot_capa": "650 W", "ps_status": "Shutdown"}
+      ]
+    },
+    "power_summary": {
+      "ps_redun_mode": "Redundant",
+      "ps_oper_mode": "Non-Redundant",
+      "tot_pwr_capacity": "650.00 W",
+      "reserve_sup": "0.00 W",
+      "pwr_used_by_mods": "76.00 W",
+      "available_pwr": "574.00 W"
+    }
+  }
+}`
+
+const showSystemResources = `{
+  "load_avg_1min": "0.29",
+  "load_avg_5min": "0.35",
+  "load_avg_15min": "0.38",
+  "processes_total": "642",
+  "processes_running": "2",
+  "cpu_state_user": "2.76",
+  "cpu_state_kernel": "1.76",
+  "cpu_state_idle": "95.47",
+  "TABLE_cpu_usage": {
+    "ROW_cpu_usage": [
+      {"cpuid": "0", "user": "3.00", "kernel": "2.00", "idle": "95.00"},
+      {"cpuid": "1", "user": "2.02", "kernel": "1.01", "idle": "96.96"},
+      {"cpuid": "2", "user": "4.95", "kernel": "2.97", "idle": "92.07"},
+      {"cpuid": "3", "user": "1.00", "kernel": "1.00", "idle": "98.00"}
+    ]
+  },
+  "memory_usage_total": "16400084",
+  "memory_usage_used": "5532428",
+  "memory_usage_free": "10867656",
+  "kernel_vmalloc_total": "0",
+  "kernel_vmalloc_free": "0",
+  "kernel_buffers": "166340",
+  "kernel_cached": "3013376",
+  "current_memory_status": "OK"
+}`
+
+const showInterfaceTransceiverDetails = `{
+  "TABLE_interface": {
+    "ROW_interface": [
+      {
+        "interface": "Ethernet1/1",
+        "sfp": "present",
+        "type": "10Gbase-SR",
+        "name": "CISCO-FINISAR",
+        "partnum": "FTLX8571D3BCL-C2",
+        "rev": "A",
+        "serialnum": "FNS17221JVQ",
+        "nom_bitrate": 10300,
+        "len_50_OM3": 300,
+        "len_625": 26,
+        "len_50": 82,
+        "ciscoid": "3",
+        "ciscoid_1": "4",
+        "cisco_part_number": "10-2415-03",
+        "cisco_product_id": "SFP-10G-SR",
+        "cisco_vendor_id": "V03",
+        "wavelength": "850",
+        "TABLE_lane": {
+          "ROW_lane": [
+            {
+              "lane_number": "1",
+              "temperature": "31.50",
+              "temp_alrm_hi": "75.00",
+              "temp_alrm_lo": "-5.00",
+              "temp_warn_hi": "70.00",
+              "temp_warn_lo": "0.00",
+              "voltage": "3.30",
+              "volt_alrm_hi": "3.63",
+              "volt_alrm_lo": "2.97",
+              "volt_warn_hi": "3.46",
+              "volt_warn_lo": "3.13",
+              "current": "6.10",
+              "current_alrm_hi": "11.80",
+              "current_alrm_lo": "4.00",
+              "current_warn_hi": "10.80",
+              "current_warn_lo": "5.00",
+              "tx_pwr": "-2.46",
+              "tx_pwr_alrm_hi": "1.69",
+              "tx_pwr_alrm_lo": "-11.30",
+              "tx_pwr_warn_hi": "-1.30",
+              "tx_pwr_warn_lo": "-7.30",
+              "rx_pwr": "-2.28",
+              "rx_pwr_alrm_hi": "1.99",
+              "rx_pwr_alrm_lo": "-13.97",
+              "rx_pwr_warn_hi": "-1.00",
+              "rx_pwr_warn_lo": "-9.91",
+              "xmit_faults": "0"
+            }
+          ]
+        }
+      },
+      {
+        "interface": "Ethernet1/2",
+        "sfp": "not present"
+      }
+    ]
+  }
+}`
+
+const showBgpSessions = `{
+  "totalpeers": "2",
+  "totalestablishedpeers": "1",
+  "localas": "65001",
+  "TABLE_vrf": {
+    "ROW_vrf": [
+      {
+        "vrf-name-out": "default",
+        "local-as": "65001",
+        "vrfpeers": "2",
+        "vrfestablishedpeers": "1",
+        "router-id": "10.1.1.1",
+        "TABLE_neighbor": {
+          "ROW_neighbor": [
+            {
+              "neighbor-id": "10.1.2.1",
+              "connectionsdropped": "3",
+              "remoteas": "65002",
+              "state": "Established",
+              "lastflap": "P2DT4H11M8S",
+              "lastread": "PT17S",
+              "lastwrite": "PT42S",
+              "localport": "179",
+              "remoteport": "51732",
+              "notificationssent": "0",
+              "notificationsreceived": "1"
+            },
+            {
+              "neighbor-id": "10.1.2.5",
+              "connectionsdropped": "0",
+              "remoteas": "65003",
+              "state": "Idle",
+              "lastflap": "P14DT2H5M",
+              "lastread": "never",
+              "lastwrite": "never",
+              "localport": "0",
+              "remoteport": "0",
+              "notificationssent": "0",
+              "notificationsreceived": "0"
+            }
+          ]
+        }
+      }
+    ]
+  }
+}`
@@ -0,0 +1,264 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simulator serves NX-API JSON-RPC responses of a Cisco Nexus
+// switch. It is used for testing the exporter without network devices.
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Faults are the failures the simulator injects into its responses.
+type Faults struct {
+	// Reject all requests with 401 Unauthorized.
+	AuthFailure bool
+	// Delay all responses.
+	Latency time.Duration
+	// Respond with 500 Internal Server Error.
+	InternalError bool
+	// Respond with a truncated JSON document.
+	MalformedJSON bool
+	// The commands affected by InternalError and MalformedJSON faults.
+	// When empty, all commands are affected.
+	Commands []string
+}
+
+// Options are the options of the simulator.
+type Options struct {
+	Hostname string
+	Username string
+	Password string
+	Faults   Faults
+}
+
+// Simulator is an NX-API endpoint of a simulated switch.
+type Simulator struct {
+	sync.RWMutex
+	opts     Options
+	requests map[string]int
+}
+
+// New returns an instance of Simulator.
+func New(opts Options) *Simulator {
+	if opts.Hostname == "" {
+		opts.Hostname = "ny-sw01"
+	}
+	if opts.Username == "" {
+		opts.Username = "admin"
+	}
+	if opts.Password == "" {
+		opts.Password = "cisco"
+	}
+	return &Simulator{
+		opts:     opts,
+		requests: make(map[string]int),
+	}
+}
+
+// SetFaults replaces the faults the simulator injects.
+func (s *Simulator) SetFaults(f Faults) {
+	s.Lock()
+	defer s.Unlock()
+	s.opts.Faults = f
+}
+
+// GetRequestCount returns the number of times a command was requested.
+func (s *Simulator) GetRequestCount(cmd string) int {
+	s.RLock()
+	defer s.RUnlock()
+	return s.requests[cmd]
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  struct {
+		Cmd     string `json:"cmd"`
+		Version int    `json:"version"`
+	} `json:"params"`
+	ID interface{} `json:"id"`
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   *rpcError   `json:"error,omitempty"`
+	ID      interface{} `json:"id"`
+}
+
+// ServeHTTP implements http.Handler. The requests are JSON-RPC calls
+// to /ins endpoint, e.g. [{"jsonrpc":"2.0","method":"cli","params":
+// {"cmd":"show version","version":1},"id":1}].
+func (s *Simulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.RLock()
+	opts := s.opts
+	s.RUnlock()
+
+	if opts.Faults.Latency > 0 {
+		time.Sleep(opts.Faults.Latency)
+	}
+	if r.URL.Path != "/ins" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	username, password, ok := r.BasicAuth()
+	if !ok || opts.Faults.AuthFailure || username != opts.Username || password != opts.Password {
+		w.Header().Set("WWW-Authenticate", `Basic realm="nxapi"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	requests := []*rpcRequest{}
+	isBatch := strings.HasPrefix(strings.TrimSpace(string(body)), "[")
+	if isBatch {
+		err = json.Unmarshal(body, &requests)
+	} else {
+		req := &rpcRequest{}
+		err = json.Unmarshal(body, req)
+		requests = append(requests, req)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusOK, &rpcResponse{
+			JSONRPC: "2.0",
+			Error:   &rpcError{Code: -32700, Message: "Parse error"},
+		})
+		return
+	}
+
+	responses := []*rpcResponse{}
+	for _, req := range requests {
+		cmd := strings.TrimSpace(req.Params.Cmd)
+		s.Lock()
+		s.requests[cmd]++
+		s.Unlock()
+		if opts.Faults.affects(cmd) {
+			if opts.Faults.InternalError {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if opts.Faults.MalformedJSON {
+				w.Header().Set("Content-Type", "application/json-rpc")
+				w.Write([]byte(`{"jsonrpc": "2.0", "result": {"body": {"host_name": "`))
+				return
+			}
+		}
+		resp := &rpcResponse{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+		}
+		if req.Method != "cli" {
+			resp.Error = &rpcError{Code: -32601, Message: "Method not found"}
+		} else if body := getResponseBody(cmd, opts.Hostname); body != nil {
+			resp.Result = map[string]interface{}{"body": body}
+		} else {
+			resp.Error = &rpcError{
+				Code:    -32602,
+				Message: "Invalid params",
+				Data:    map[string]string{"msg": "% Invalid command\n"},
+			}
+		}
+		responses = append(responses, resp)
+	}
+	if !isBatch || len(responses) == 1 {
+		writeJSON(w, http.StatusOK, responses[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, responses)
+}
+
+func (f Faults) affects(cmd string) bool {
+	if len(f.Commands) == 0 {
+		return true
+	}
+	for _, c := range f.Commands {
+		if c == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json-rpc")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// TestServer is a simulator listening on loopback interface.
+type TestServer struct {
+	*Simulator
+	server *httptest.Server
+	URL    string
+	Host   string
+	Port   int
+}
+
+// NewTestServer starts a simulator on a random port of loopback
+// interface. The caller must call Close when finished.
+func NewTestServer(opts Options) (*TestServer, error) {
+	sim := New(opts)
+	server := httptest.NewServer(sim)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		server.Close()
+		return nil, fmt.Errorf("error parsing simulator address: %s", err)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		server.Close()
+		return nil, fmt.Errorf("error parsing simulator port: %s", err)
+	}
+	return &TestServer{
+		Simulator: sim,
+		server:    server,
+		URL:       server.URL,
+		Host:      host,
+		Port:      p,
+	}, nil
+}
+
+// Close shuts down the simulator.
+func (ts *TestServer) Close() {
+	ts.server.Close()
+}
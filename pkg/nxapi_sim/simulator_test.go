@@ -0,0 +1,110 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func sendCommand(t *testing.T, ts *TestServer, username, password, cmd string) (int, []byte) {
+	data := []byte(`[{"jsonrpc": "2.0", "method": "cli", "params": {"cmd": "` + cmd + `", "version": 1}, "id": 1}]`)
+	req, err := http.NewRequest("POST", ts.URL+"/ins", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json-rpc")
+	req.SetBasicAuth(username, password)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return resp.StatusCode, body
+}
+
+func TestSimulator(t *testing.T) {
+	ts, err := NewTestServer(Options{Hostname: "ny-sw09"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer ts.Close()
+
+	commands := []string{
+		"show version",
+		"show interface",
+		"show vlan",
+		"show environment",
+		"show system resources",
+		"show interface transceiver details",
+		"show bgp sessions",
+	}
+	for _, cmd := range commands {
+		code, body := sendCommand(t, ts, "admin", "cisco", cmd)
+		if code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, but got %d", cmd, http.StatusOK, code)
+		}
+		resp := &struct {
+			Result struct {
+				Body map[string]interface{} `json:"body"`
+			} `json:"result"`
+		}{}
+		if err := json.Unmarshal(body, resp); err != nil {
+			t.Fatalf("%s: unexpected error: %s", cmd, err)
+		}
+		if len(resp.Result.Body) == 0 {
+			t.Errorf("%s: expected response body, but got %s", cmd, body)
+		}
+		if cmd == "show version" && resp.Result.Body["host_name"] != "ny-sw09" {
+			t.Errorf("expected hostname ny-sw09, but got %v", resp.Result.Body["host_name"])
+		}
+	}
+	if code, _ := sendCommand(t, ts, "admin", "cisco", "show bogus"); code != http.StatusOK {
+		t.Errorf("expected status %d for invalid command, but got %d", http.StatusOK, code)
+	}
+
+	if code, _ := sendCommand(t, ts, "admin", "wrong", "show version"); code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, but got %d", http.StatusUnauthorized, code)
+	}
+	ts.SetFaults(Faults{AuthFailure: true})
+	if code, _ := sendCommand(t, ts, "admin", "cisco", "show version"); code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, but got %d", http.StatusUnauthorized, code)
+	}
+
+	ts.SetFaults(Faults{InternalError: true, Commands: []string{"show vlan"}})
+	if code, _ := sendCommand(t, ts, "admin", "cisco", "show vlan"); code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, but got %d", http.StatusInternalServerError, code)
+	}
+	if code, _ := sendCommand(t, ts, "admin", "cisco", "show version"); code != http.StatusOK {
+		t.Errorf("expected status %d for unaffected command, but got %d", http.StatusOK, code)
+	}
+
+	ts.SetFaults(Faults{MalformedJSON: true})
+	_, body := sendCommand(t, ts, "admin", "cisco", "show version")
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		t.Errorf("expected malformed JSON, but got %s", body)
+	}
+	if n := ts.GetRequestCount("show version"); n != 3 {
+		t.Errorf("expected 3 authenticated show version requests, but got %d", n)
+	}
+}